@@ -0,0 +1,52 @@
+package dotconfig
+
+// defaultAppendSeparator joins values for KEY+=value lines when no
+// [AppendSeparator] option is supplied.
+const defaultAppendSeparator = ","
+
+// AppendSeparator sets the string used to join values for KEY+=value
+// lines. The default is a comma, which suits lists like feature flags.
+// For PATH-like values, use a colon:
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.AppendSeparator(":"))
+func AppendSeparator(sep string) DecodeOption {
+	return funcOption(func(o *options) {
+		o.AppendSeparator = &sep
+	})
+}
+
+// EntryValues returns the value each key in entries ends up with, the
+// way loading them would set it: entries for sections other than the
+// [Profile] are left out, later entries override earlier ones, and
+// KEY+=value lines are added on with the [AppendSeparator]. It's for
+// tools that read env files with [Parse] but want the same values as
+// dotconfig. The environment isn't used, so KEY+=value only adds to
+// earlier entries.
+func EntryValues(entries []Entry, opts ...DecodeOption) map[string]string {
+	o := optsFromVariadic(opts)
+	values := map[string]string{}
+	o.Environ, o.private = values, nil
+	for _, entry := range o.profileEntries(entries) {
+		if entry.Append {
+			entry.Value = o.appendValue(entry.Key, entry.Value)
+		}
+		values[entry.Key] = entry.Value
+	}
+	return values
+}
+
+// appendValue returns the value for a KEY+=value line: value added to
+// the end of key's current value. Since earlier files and lines have
+// already been set in the environment, that's where we look. If key
+// isn't set (or is empty), value is used on its own.
+func (o options) appendValue(key, value string) string {
+	existing, ok := o.getenv(key)
+	if !ok || existing == "" {
+		return value
+	}
+	sep := defaultAppendSeparator
+	if o.AppendSeparator != nil {
+		sep = *o.AppendSeparator
+	}
+	return existing + sep + value
+}
@@ -0,0 +1,107 @@
+package dotconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// sourceDefault is the source recorded in the audit log for fields set
+// from their default tag.
+const sourceDefault = "default"
+
+// AuditLog writes a record of each load to w as a line of JSON. The
+// record lists the keys that were resolved, which field and source each
+// came from, the keys that failed, and when the load happened. It never
+// includes values, so it's safe to keep for services that read secrets
+// at startup:
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.AuditLog(log.Writer()))
+//
+// If the record can't be written, the load fails.
+func AuditLog(w io.Writer) DecodeOption {
+	return funcOption(func(o *options) {
+		o.AuditLog = w
+	})
+}
+
+// AuditRecord is the JSON written by [AuditLog] for each load.
+type AuditRecord struct {
+	// Started and Finished are when decoding started and finished.
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	// Keys are the keys fields were set from, in field order.
+	Keys []AuditKey `json:"keys"`
+	// Sources are the sources values were read from, like "file" or a
+	// name from [NamedSource], in the order they were first used.
+	Sources []string `json:"sources"`
+	// Failed are the keys of fields that couldn't be decoded.
+	Failed []string `json:"failed,omitempty"`
+}
+
+// AuditKey records where a field's value came from.
+type AuditKey struct {
+	Field string `json:"field"`
+	Key   string `json:"key"`
+	// Source is where the value came from: "file", "env", "flag",
+	// "default", or a name from [NamedSource].
+	Source string `json:"source"`
+}
+
+// writeAudit writes the audit record for a load that started at
+// started and returned err, and returns err or the error writing the
+// record.
+func (o options) writeAudit(res *Result, started time.Time, err error) error {
+	rec := AuditRecord{
+		Started:  started,
+		Finished: time.Now(),
+		Keys:     res.resolved,
+		Sources:  []string{},
+		Failed:   failedKeys(err),
+	}
+	if rec.Keys == nil {
+		rec.Keys = []AuditKey{}
+	}
+	seen := map[string]bool{}
+	for _, key := range rec.Keys {
+		if !seen[key.Source] {
+			seen[key.Source] = true
+			rec.Sources = append(rec.Sources, key.Source)
+		}
+	}
+	if werr := json.NewEncoder(o.AuditLog).Encode(rec); werr != nil {
+		werr = fmt.Errorf("writing audit log: %w", werr)
+		if err == nil {
+			return werr
+		}
+		return joinError{errs: []error{err, werr}}
+	}
+	return err
+}
+
+// failedKeys returns the keys (or field names, for errors without one)
+// of the field errors in err.
+func failedKeys(err error) []string {
+	var errs []error
+	var je joinError
+	if errors.As(err, &je) {
+		errs = je.errs
+	} else if err != nil {
+		errs = []error{err}
+	}
+	var keys []string
+	for _, err := range errs {
+		var fe *FieldError
+		if !errors.As(err, &fe) {
+			continue
+		}
+		if fe.Key != "" {
+			keys = append(keys, fe.Key)
+		} else {
+			keys = append(keys, fe.Field)
+		}
+	}
+	return keys
+}
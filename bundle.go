@@ -0,0 +1,38 @@
+package dotconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// FromBase64Env reads a whole env file from the environment variable
+// key, base64 encoded, and then decodes a T like [FromReader]. It's for
+// platforms that only let you set plain environment variables when you
+// need multi-line values like certificates:
+//
+//	// DOTCONFIG_B64=$(base64 -w0 .env)
+//	conf, err := dotconfig.FromBase64Env[AppConfig]("DOTCONFIG_B64")
+//
+// Whitespace in the encoded value is ignored, so wrapped base64 output
+// works too. If key isn't set, values come from the environment like
+// they do when [FromFileName] can't find its file, unless the
+// [ReturnFileIOErrors] option is set.
+func FromBase64Env[T any](key string, opts ...DecodeOption) (T, error) {
+	ops := optsFromVariadic(opts)
+	encoded, ok := ops.getenv(key)
+	if !ok {
+		if ops.ReturnFileIOErrors {
+			var config T
+			return config, &FieldError{Key: key, Err: ErrMissingEnvVar}
+		}
+		return fromEnv[T](ops, &Result{})
+	}
+	payload, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		var config T
+		return config, fmt.Errorf("decoding %v: %w", key, err)
+	}
+	return FromReader[T](bytes.NewReader(payload), opts...)
+}
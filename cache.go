@@ -0,0 +1,69 @@
+package dotconfig
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// CachedLoader loads a config from a file with [FromFileName], and only
+// does it again when the file's modification time or size changes. It's
+// for CLIs and tools that hit the load path many times per run:
+//
+//	loader := dotconfig.NewCachedLoader[AppConfig](".env")
+//	// ...
+//	conf, err := loader.Load()
+//
+// Since a cached config isn't decoded again, changes to the environment
+// aren't picked up until the file changes. Failed loads aren't cached.
+// A CachedLoader is safe for concurrent use.
+type CachedLoader[T any] struct {
+	name string
+	opts []DecodeOption
+
+	mu      sync.Mutex
+	loaded  bool
+	exists  bool
+	modTime time.Time
+	size    int64
+	config  T
+}
+
+// NewCachedLoader returns a [CachedLoader] for the file called name.
+// opts are passed to [FromFileName].
+func NewCachedLoader[T any](name string, opts ...DecodeOption) *CachedLoader[T] {
+	return &CachedLoader[T]{name: name, opts: opts}
+}
+
+// Load returns the cached config if the file hasn't changed since the
+// last successful load, and otherwise loads it again.
+func (l *CachedLoader[T]) Load() (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	info, err := os.Stat(l.name)
+	exists := err == nil
+	var (
+		modTime time.Time
+		size    int64
+	)
+	if exists {
+		modTime, size = info.ModTime(), info.Size()
+	}
+	if l.loaded && exists == l.exists && modTime.Equal(l.modTime) && size == l.size {
+		return l.config, nil
+	}
+	config, err := FromFileName[T](l.name, l.opts...)
+	if err != nil {
+		l.loaded = false
+		return config, err
+	}
+	l.loaded, l.exists, l.modTime, l.size, l.config = true, exists, modTime, size, config
+	return config, nil
+}
+
+// Reset clears the cache so the next call to Load reads the file again.
+func (l *CachedLoader[T]) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.loaded = false
+}
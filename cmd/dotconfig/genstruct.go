@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+// runGenStruct reads an env file and prints a Go struct with a tagged
+// field for each key, with types inferred from the values.
+func runGenStruct(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("genstruct", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: dotconfig genstruct [flags] file")
+		fs.PrintDefaults()
+	}
+	typeName := fs.String("name", "Config", "`name` of the generated struct")
+	pkg := fs.String("package", "main", "package `name` for the generated file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "dotconfig genstruct: expected one env file")
+		fs.Usage()
+		return 2
+	}
+	if !token.IsIdentifier(*typeName) || !token.IsIdentifier(*pkg) {
+		fmt.Fprintln(stderr, "dotconfig genstruct: -name and -package must be Go identifiers")
+		return 2
+	}
+	name := fs.Arg(0)
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "dotconfig genstruct: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+	// The file is the developer's own, so includes are followed like
+	// they are when it's loaded.
+	entries, err := dotconfig.Parse(r, dotconfig.FollowIncludes)
+	if err != nil {
+		fmt.Fprintf(stderr, "dotconfig genstruct: %v\n", err)
+		return 1
+	}
+	src, err := genStruct(entries, *pkg, *typeName)
+	if err != nil {
+		fmt.Fprintf(stderr, "dotconfig genstruct: %v\n", err)
+		return 1
+	}
+	stdout.Write(src)
+	return 0
+}
+
+// genField is a struct field inferred from one or more entries with
+// the same key.
+type genField struct {
+	key  string
+	typ  string
+	desc string
+}
+
+// genStruct returns formatted Go source for a struct named typeName
+// with a field for each key in entries, in the order they first
+// appear.
+func genStruct(entries []dotconfig.Entry, pkg, typeName string) ([]byte, error) {
+	var fields []*genField
+	byKey := map[string]*genField{}
+	for _, e := range entries {
+		typ := e.Schema.Type
+		if typ == "" {
+			typ = inferType(e.Value)
+		}
+		if _, ok := schemaTypes[typ]; !ok {
+			return nil, fmt.Errorf("line %v: %v has unknown type %q", e.Line, e.Key, typ)
+		}
+		f, ok := byKey[e.Key]
+		if !ok {
+			f = &genField{key: e.Key, typ: typ}
+			byKey[e.Key] = f
+			fields = append(fields, f)
+		} else {
+			// The same key can show up in several profiles or be
+			// appended to. Pick a type that fits every value.
+			f.typ = widenType(f.typ, typ)
+		}
+		if e.Schema.Desc != "" {
+			f.desc = e.Schema.Desc
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %v\n\n", pkg)
+	for _, f := range fields {
+		if f.typ == "duration" {
+			b.WriteString("import \"time\"\n\n")
+			break
+		}
+	}
+	fmt.Fprintf(&b, "type %v struct {\n", typeName)
+	names := map[string]bool{}
+	for _, f := range fields {
+		// Field names have to be unique, same as in schema.structType.
+		base, err := fieldName(f.key)
+		if err != nil {
+			return nil, err
+		}
+		name := base
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%v%d", base, i)
+		}
+		names[name] = true
+		tag := fmt.Sprintf("env:%q", f.key)
+		if f.desc != "" {
+			tag += fmt.Sprintf(" desc:%q", f.desc)
+		}
+		fmt.Fprintf(&b, "%v %v %v\n", name, goTypes[f.typ], "`"+tag+"`")
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// goTypes maps schema types to the Go type written for their field.
+var goTypes = map[string]string{
+	"":         "string",
+	"string":   "string",
+	"int":      "int",
+	"float":    "float64",
+	"bool":     "bool",
+	"duration": "time.Duration",
+}
+
+// inferType returns the schema type that best fits value. Anything
+// that isn't clearly a number, bool or duration is a string, including
+// empty values.
+func inferType(value string) string {
+	switch {
+	case value == "":
+		return "string"
+	case strings.EqualFold(value, "true") || strings.EqualFold(value, "false"):
+		// Not strconv.ParseBool, which would make 0 and 1 bools.
+		return "bool"
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "int"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil && !strings.ContainsAny(value, "nN") {
+		// ParseFloat accepts NaN and Inf, which are more likely words.
+		return "float"
+	}
+	if _, err := time.ParseDuration(value); err == nil {
+		return "duration"
+	}
+	return "string"
+}
+
+// widenType returns a type that fits values of both a and b.
+func widenType(a, b string) string {
+	switch {
+	case a == b:
+		return a
+	case a == "int" && b == "float", a == "float" && b == "int":
+		return "float"
+	}
+	return "string"
+}
@@ -0,0 +1,78 @@
+// Command dotconfig is a tool for working with env files outside of the
+// app that reads them.
+//
+// Usage:
+//
+//	dotconfig print --type schema.json --env .env
+//	dotconfig genstruct .env
+//	dotconfig pull -cmd "vault kv get -format=env secret/myapp" -type schema.json
+//
+// The print command resolves config the same way the app would (files,
+// then the environment, then defaults) and prints the values it would
+// see, with secrets redacted.
+//
+// Since the tool can't see your Go types, it reads the fields from a
+// JSON schema instead:
+//
+//	{
+//		"fields": [
+//			{"key": "PORT", "type": "int", "default": "8080"},
+//			{"key": "STRIPE_KEY", "secret": true, "desc": "Stripe API key"},
+//			{"key": "DEBUG", "type": "bool", "optional": true}
+//		]
+//	}
+//
+// Types are the same as for "# dotconfig:" schema comments: string (the
+// default), int, float, bool and duration.
+//
+// The genstruct command goes the other way for projects that already
+// have an env file: it prints a Go struct with a tagged field for each
+// key, guessing each field's type from its value. Types from schema
+// comments win over guesses.
+//
+// The pull command scaffolds a developer's local env file from a shared
+// source: an envdir directory, or any command that prints an env file.
+// Secret values, from the schema or -secret, are left blank unless
+// -include-secrets is passed.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: dotconfig <command> [flags]
+
+Commands:
+  print      Print the values an app would see, with secrets redacted
+  genstruct  Generate a Go struct from an existing env file
+  pull       Write a local env file from a remote source
+
+Run "dotconfig <command> -h" for a command's flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command in args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "print":
+		return runPrint(args[1:], stdout, stderr)
+	case "genstruct":
+		return runGenStruct(args[1:], stdout, stderr)
+	case "pull":
+		return runPull(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	fmt.Fprintf(stderr, "dotconfig: unknown command %q\n\n%v", args[0], usage)
+	return 2
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+func TestPrint(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "schema.json")
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(schemaFile, []byte(`{"fields": [
+		{"key": "PRINT_PORT", "type": "int", "default": "8080"},
+		{"key": "PRINT_STRIPE_KEY", "secret": true},
+		{"key": "PRINT_TIMEOUT", "type": "duration"},
+		{"key": "PRINT_GREETING"},
+		{"key": "PRINT_DEBUG", "type": "bool", "optional": true}
+	]}`), 0o600)
+	os.WriteFile(envFile, []byte("PRINT_STRIPE_KEY=sk_test_123\nPRINT_TIMEOUT=1m30s\nPRINT_GREETING='hello world'"), 0o600)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"print", "--type", schemaFile, "--env", envFile}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	expected := `PRINT_PORT=8080               # default
+PRINT_STRIPE_KEY=***          # env
+PRINT_TIMEOUT=1m30s           # env
+PRINT_GREETING="hello world"  # env
+PRINT_DEBUG=false             # unset
+`
+	if stdout.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, stdout.String())
+	}
+	if strings.Contains(stdout.String(), "sk_test_123") {
+		t.Errorf("Expected secret to be redacted. Got:\n%v", stdout.String())
+	}
+}
+
+func TestPrintErrors(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "schema.json")
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(schemaFile, []byte(`{"fields": [{"key": "PRINT_MISSING"}, {"key": "PRINT_COUNT", "type": "int"}]}`), 0o600)
+	os.WriteFile(envFile, []byte("PRINT_COUNT=3"), 0o600)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"print", "-type", schemaFile, "-env", envFile}, &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1. Got %v.", code)
+	}
+	if !strings.Contains(stdout.String(), "PRINT_COUNT=3") {
+		t.Errorf("Expected resolved values to be printed. Got:\n%v", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "PRINT_MISSING") {
+		t.Errorf("Expected error for PRINT_MISSING. Got:\n%v", stderr.String())
+	}
+
+	stderr.Reset()
+	os.WriteFile(schemaFile, []byte(`{"fields": [{"key": "PRINT_X", "type": "complex"}]}`), 0o600)
+	if code := run([]string{"print", "-type", schemaFile, "-env", envFile}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), `unknown type "complex"`) {
+		t.Errorf("Expected unknown type error. Got %v: %v", code, stderr.String())
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"DATABASE_URL":  "DatabaseURL",
+		"port":          "Port",
+		"app.log-level": "AppLogLevel",
+		"2FA_ENABLED":   "Field2faEnabled",
+		"größe_MAX":     "GrößeMax",
+		"élan":          "Élan",
+		"数据_URL":        "Field数据URL",
+	} {
+		got, err := fieldName(key)
+		if err != nil {
+			t.Errorf("Didn't expect error for %q. Got %v.", key, err)
+		}
+		if got != expected {
+			t.Errorf("Expected %q. Got %q.", expected, got)
+		}
+	}
+}
+
+func TestNonASCIIKeys(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "schema.json")
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(schemaFile, []byte(`{"fields": [{"key": "größe"}, {"key": "数据"}]}`), 0o600)
+	os.WriteFile(envFile, []byte("größe=10\n数据=abc\n"), 0o600)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"print", "-type", schemaFile, "-env", envFile}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "größe=10") || !strings.Contains(stdout.String(), "数据=abc") {
+		t.Errorf("Expected values to be printed. Got:\n%v", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"genstruct", envFile}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Größe ") || !strings.Contains(stdout.String(), "Field数据 ") {
+		t.Errorf("Expected non-ASCII field names. Got:\n%v", stdout.String())
+	}
+}
+
+func TestGenStruct(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(envFile, []byte(`DATABASE_URL=postgres://localhost/app
+PORT=8080
+RATE=0.5
+DEBUG=true
+TIMEOUT=30s
+# dotconfig: type=string, desc=Numeric but really a string
+ZIP_CODE=97201
+EMPTY=
+[prod]
+PORT=443
+RATE=1
+`), 0o600)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"genstruct", "-name", "AppConfig", envFile}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	expected := "package main\n\nimport \"time\"\n\ntype AppConfig struct {\n" +
+		"\tDatabaseURL string        `env:\"DATABASE_URL\"`\n" +
+		"\tPort        int           `env:\"PORT\"`\n" +
+		"\tRate        float64       `env:\"RATE\"`\n" +
+		"\tDebug       bool          `env:\"DEBUG\"`\n" +
+		"\tTimeout     time.Duration `env:\"TIMEOUT\"`\n" +
+		"\tZipCode     string        `env:\"ZIP_CODE\" desc:\"Numeric but really a string\"`\n" +
+		"\tEmpty       string        `env:\"EMPTY\"`\n" +
+		"}\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, stdout.String())
+	}
+
+	if code := run([]string{"genstruct"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without a file. Got %v.", code)
+	}
+}
+
+func TestGenStructDuration(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(envFile, []byte("TIMEOUT=5s"), 0o600)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"genstruct", envFile}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	expected := "package main\n\nimport \"time\"\n\ntype Config struct {\n" +
+		"\tTimeout time.Duration `env:\"TIMEOUT\"`\n" +
+		"}\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, stdout.String())
+	}
+
+	// The generated field decodes the value it was generated from.
+	type generated struct {
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+	config, err := dotconfig.FromFileName[generated](envFile, dotconfig.WithEnviron(map[string]string{}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Timeout != 5*time.Second {
+		t.Errorf("Expected %v. Got %v.", 5*time.Second, config.Timeout)
+	}
+}
+
+func TestInferType(t *testing.T) {
+	for value, expected := range map[string]string{
+		"42":        "int",
+		"-7":        "int",
+		"3.14":      "float",
+		"NaN":       "string",
+		"TRUE":      "bool",
+		"1":         "int",
+		"1h30m":     "duration",
+		"localhost": "string",
+		"":          "string",
+	} {
+		if got := inferType(value); got != expected {
+			t.Errorf("%q: expected %q. Got %q.", value, expected, got)
+		}
+	}
+}
+
+func TestPull(t *testing.T) {
+	dir := t.TempDir()
+	secretsDir := filepath.Join(dir, "secrets")
+	os.Mkdir(secretsDir, 0o700)
+	os.WriteFile(filepath.Join(secretsDir, "DB_PASSWORD"), []byte("hunter2\n"), 0o600)
+	os.WriteFile(filepath.Join(secretsDir, "GREETING"), []byte("hello world"), 0o600)
+	os.WriteFile(filepath.Join(secretsDir, "PORT"), []byte("8080"), 0o600)
+	out := filepath.Join(dir, ".env")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"pull", "-dir", secretsDir, "-secret", "DB_PASSWORD", "-out", out}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	b, _ := os.ReadFile(out)
+	expected := `# DB_PASSWORD is secret. Fill it in, or pull with -include-secrets.
+DB_PASSWORD=
+GREETING="hello world"
+PORT=8080
+`
+	if string(b) != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, string(b))
+	}
+
+	if code := run([]string{"pull", "-dir", secretsDir, "-out", out}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "-force") {
+		t.Errorf("Expected existing file to be kept. Got %v: %v", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"pull", "-dir", secretsDir, "-secret", "DB_PASSWORD", "-include-secrets", "-out", "-"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "DB_PASSWORD=hunter2\n") {
+		t.Errorf("Expected secret to be included. Got:\n%v", stdout.String())
+	}
+}
+
+func TestPullCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"pull", "-cmd", `printf 'API_KEY=abc\nREGION="us west"\n'`, "-out", "-"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0. Got %v: %v", code, stderr.String())
+	}
+	if expected := "API_KEY=abc\nREGION=\"us west\"\n"; stdout.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, stdout.String())
+	}
+
+	if code := run([]string{"pull", "-cmd", "echo denied >&2; exit 1", "-out", "-"}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "denied") {
+		t.Errorf("Expected command error. Got %v: %v", code, stderr.String())
+	}
+}
+
+func TestWriteEnvRoundTrip(t *testing.T) {
+	values := map[string]string{}
+	for i, value := range []string{
+		"", " lead", "trail ", "a b", "tab\there", "#hash", "a#b", "it's", `say "hi"`,
+		`"quoted"`, "'single'", `"`, "'", "multi\nline", "\n", `back\slash`, `ends\`,
+		"back\\\nslash", "cr\r", "=eq=", "[section]", "émoji 🎉",
+	} {
+		values[fmt.Sprintf("KEY_%02d", i)] = value
+	}
+	var b bytes.Buffer
+	if err := writeEnv(&b, values, nil, false); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	entries, err := dotconfig.Parse(&b)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if len(entries) != len(values) {
+		t.Fatalf("Expected %v entries. Got %v.", len(values), len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Value != values[entry.Key] {
+			t.Errorf("Expected %v to read back as %q. Got %q.", entry.Key, values[entry.Key], entry.Value)
+		}
+	}
+
+	for _, value := range []string{"a #b", `literal\n`} {
+		if err := writeEnv(&b, map[string]string{"KEY": value}, nil, false); err == nil {
+			t.Errorf("Expected error writing %q.", value)
+		}
+	}
+}
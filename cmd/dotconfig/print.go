@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+// runPrint resolves config described by a schema and prints the values
+// an app would see, with secrets redacted.
+func runPrint(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("print", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaFile := fs.String("type", "", "JSON `file` describing the config's fields (required)")
+	envFile := fs.String("env", ".env", "env `file` to load, or - for stdin")
+	asJSON := fs.Bool("json", false, "print a JSON array instead of KEY=value lines")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaFile == "" {
+		fmt.Fprintln(stderr, "dotconfig print: -type is required")
+		fs.Usage()
+		return 2
+	}
+	s, err := readSchema(*schemaFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "dotconfig print: %v\n", err)
+		return 1
+	}
+	typ, err := s.structType()
+	if err != nil {
+		fmt.Fprintf(stderr, "dotconfig print: %v\n", err)
+		return 1
+	}
+	if err := loadFile(*envFile); err != nil {
+		fmt.Fprintf(stderr, "dotconfig print: %v\n", err)
+		return 1
+	}
+	// Decode with AllowPartial so the values that did resolve are still
+	// printed alongside the errors for the ones that didn't.
+	config := reflect.New(typ).Interface()
+	decodeErr := dotconfig.Decode(config, dotconfig.AllowPartial)
+	infos := dotconfig.Explain(config)
+	if *asJSON {
+		if infos == nil {
+			infos = []dotconfig.FieldInfo{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(infos); err != nil {
+			fmt.Fprintf(stderr, "dotconfig print: %v\n", err)
+			return 1
+		}
+	} else {
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		for _, info := range infos {
+			// This is a report, so values an env file can't hold are
+			// still shown, Go quoted.
+			value, err := quote(info.Value)
+			if err != nil {
+				value = strconv.Quote(info.Value)
+			}
+			fmt.Fprintf(tw, "%v=%v\t# %v\n", info.Key, value, info.Origin)
+		}
+		tw.Flush()
+	}
+	if decodeErr != nil {
+		for _, err := range dotconfig.Errors(decodeErr) {
+			fmt.Fprintf(stderr, "dotconfig print: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// loadFile loads the env file name into the environment. The name -
+// reads from stdin.
+func loadFile(name string) error {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	_, err := dotconfig.Load(r)
+	return err
+}
+
+// errUnquotable is returned by quote for values an env file can't hold.
+var errUnquotable = errors.New(`values with " #" or a literal \n can't be written to an env file`)
+
+// quote double quotes value if it wouldn't read back the same unquoted.
+// The only escape env files have is \n for a newline, and " #" starts a
+// comment even in quotes, so values with either are an error.
+func quote(value string) (string, error) {
+	if strings.Contains(value, " #") || strings.Contains(value, `\n`) {
+		return "", errUnquotable
+	}
+	if value != "" && !strings.ContainsAny(value, " \t\n\r#'\"\\") {
+		return value, nil
+	}
+	return `"` + strings.ReplaceAll(value, "\n", `\n`) + `"`, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+// runPull fetches values from a source and writes them to a local env
+// file, with secrets left blank unless asked for.
+func runPull(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("pull", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dir := fs.String("dir", "", "envdir `directory` to read, like a mounted secrets volume")
+	command := fs.String("cmd", "", "shell `command` that prints an env file, like \"vault kv get -format=env secret/myapp\"")
+	out := fs.String("out", ".env", "env `file` to write, or - for stdout")
+	schemaFile := fs.String("type", "", "JSON `file` describing the config's fields, used to find secrets")
+	secretKeys := fs.String("secret", "", "comma-separated `keys` to treat as secret, on top of any from -type")
+	includeSecrets := fs.Bool("include-secrets", false, "write secret values instead of leaving them blank")
+	force := fs.Bool("force", false, "overwrite the output file if it exists")
+	timeout := fs.Duration("timeout", 30*time.Second, "how long to wait for the source")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	var src dotconfig.Source
+	switch {
+	case *dir != "" && *command != "":
+		fmt.Fprintln(stderr, "dotconfig pull: use only one of -dir and -cmd")
+		return 2
+	case *dir != "":
+		src = dotconfig.NamedSource("dir", dotconfig.EnvDir(*dir))
+	case *command != "":
+		src = dotconfig.NamedSource("cmd", commandSource(*command))
+	default:
+		fmt.Fprintln(stderr, "dotconfig pull: one of -dir or -cmd is required")
+		fs.Usage()
+		return 2
+	}
+
+	secrets := map[string]bool{}
+	for _, key := range strings.Split(*secretKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			secrets[key] = true
+		}
+	}
+	if *schemaFile != "" {
+		s, err := readSchema(*schemaFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "dotconfig pull: %v\n", err)
+			return 1
+		}
+		for _, f := range s.Fields {
+			if f.Secret {
+				secrets[f.Key] = true
+			}
+		}
+	}
+
+	values, err := dotconfig.TimeoutSource(src, *timeout).Fetch(context.Background())
+	if err != nil {
+		fmt.Fprintf(stderr, "dotconfig pull: %v\n", err)
+		return 1
+	}
+	var b bytes.Buffer
+	if err := writeEnv(&b, values, secrets, *includeSecrets); err != nil {
+		fmt.Fprintf(stderr, "dotconfig pull: %v\n", err)
+		return 1
+	}
+
+	if *out == "-" {
+		stdout.Write(b.Bytes())
+		return 0
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !*force {
+		flags |= os.O_EXCL
+	}
+	// The file can hold secrets, so only the owner can read it.
+	f, err := os.OpenFile(*out, flags, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		fmt.Fprintf(stderr, "dotconfig pull: %v already exists; use -force to overwrite it\n", *out)
+		return 1
+	}
+	if err == nil {
+		_, err = f.Write(b.Bytes())
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "dotconfig pull: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "dotconfig pull: wrote %d keys to %v\n", len(values), *out)
+	return 0
+}
+
+// writeEnv writes values to w as an env file in sorted order. Keys in
+// secrets are left blank with a comment saying so, unless
+// includeSecrets is true. Values that an env file can't hold are an
+// error.
+func writeEnv(w io.Writer, values map[string]string, secrets map[string]bool, includeSecrets bool) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if secrets[key] && !includeSecrets {
+			fmt.Fprintf(w, "# %v is secret. Fill it in, or pull with -include-secrets.\n%v=\n", key, key)
+			continue
+		}
+		value, err := quote(values[key])
+		if err != nil {
+			return fmt.Errorf("%v: %w", key, err)
+		}
+		fmt.Fprintf(w, "%v=%v\n", key, value)
+	}
+	return nil
+}
+
+// commandSource returns a [dotconfig.Source] that runs command with the
+// shell and parses what it prints as an env file. It's the escape hatch
+// for backends the tool can't talk to itself, since most of them have a
+// CLI that can print env format.
+func commandSource(command string) dotconfig.Source {
+	return dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("running %q: %w: %v", command, err, msg)
+			}
+			return nil, fmt.Errorf("running %q: %w", command, err)
+		}
+		entries, err := dotconfig.Parse(bytes.NewReader(output))
+		if err != nil {
+			return nil, fmt.Errorf("parsing output of %q: %w", command, err)
+		}
+		values := map[string]string{}
+		for _, e := range entries {
+			values[e.Key] = e.Value
+		}
+		return values, nil
+	})
+}
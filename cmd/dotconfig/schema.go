@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// schema describes the fields of a config for commands that can't see
+// its Go type.
+type schema struct {
+	Fields []schemaField `json:"fields"`
+}
+
+// schemaField describes a single field.
+type schemaField struct {
+	Key      string  `json:"key"`
+	Type     string  `json:"type,omitempty"`
+	Default  *string `json:"default,omitempty"`
+	Optional bool    `json:"optional,omitempty"`
+	Secret   bool    `json:"secret,omitempty"`
+	Desc     string  `json:"desc,omitempty"`
+}
+
+// schemaTypes maps schema types to the Go type of their field.
+var schemaTypes = map[string]reflect.Type{
+	"":         reflect.TypeFor[string](),
+	"string":   reflect.TypeFor[string](),
+	"int":      reflect.TypeFor[int64](),
+	"float":    reflect.TypeFor[float64](),
+	"bool":     reflect.TypeFor[bool](),
+	"duration": reflect.TypeFor[duration](),
+}
+
+// readSchema reads a schema from the JSON file name.
+func readSchema(name string) (schema, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return schema{}, err
+	}
+	var s schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return schema{}, fmt.Errorf("reading schema %v: %w", name, err)
+	}
+	return s, nil
+}
+
+// structType returns a struct type with a tagged field for each field
+// in s, so it can be decoded like any other config.
+func (s schema) structType() (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, len(s.Fields))
+	seen := map[string]bool{}
+	names := map[string]bool{}
+	for _, f := range s.Fields {
+		if f.Key == "" {
+			return nil, errors.New("schema field is missing a key")
+		}
+		if seen[f.Key] {
+			return nil, fmt.Errorf("schema field %v is listed more than once", f.Key)
+		}
+		seen[f.Key] = true
+		typ, ok := schemaTypes[f.Type]
+		if !ok {
+			return nil, fmt.Errorf("schema field %v has unknown type %q", f.Key, f.Type)
+		}
+		tag := f.Key
+		if f.Optional {
+			tag += ",optional"
+		}
+		if f.Secret {
+			tag += ",secret"
+		}
+		tags := []string{fmt.Sprintf("env:%q", tag)}
+		if f.Default != nil {
+			tags = append(tags, fmt.Sprintf("default:%q", *f.Default))
+		}
+		if f.Desc != "" {
+			tags = append(tags, fmt.Sprintf("desc:%q", f.Desc))
+		}
+		// Field names have to be unique, and keys like A_B and A.B
+		// would get the same one.
+		base, err := fieldName(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		name := base
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%v%d", base, i)
+		}
+		names[name] = true
+		fields = append(fields, reflect.StructField{
+			Name: name,
+			Type: typ,
+			Tag:  reflect.StructTag(strings.Join(tags, " ")),
+		})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// fieldName turns a key like DATABASE_URL into a Go field name like
+// DatabaseURL. Keys that don't start with an upper case letter once
+// converted, like 2FA_ENABLED, get a Field prefix so the field is
+// exported.
+func fieldName(key string) (string, error) {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if initialisms[strings.ToUpper(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		b.WriteRune(unicode.ToUpper(first))
+		b.WriteString(strings.ToLower(word[size:]))
+	}
+	name := b.String()
+	if first, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(first) {
+		name = "Field" + name
+	}
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("can't make a Go field name for key %q", key)
+	}
+	return name, nil
+}
+
+// initialisms are words that stay upper case in field names, like the
+// ones golint knows about.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DB": true, "DNS": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// duration is a [time.Duration] that decodes from strings like "5s".
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
@@ -0,0 +1,132 @@
+package dotconfig
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// EnvconfigCompat decodes structs tagged for
+// github.com/kelseyhightower/envconfig, so existing configs can move to
+// dotconfig without retagging every field. It's the equivalent of
+// envconfig.Process(prefix, &config):
+//
+//	type AppConfig struct {
+//		MaxBytes int    `split_words:"true" default:"1024"`
+//		APIKey   string `envconfig:"API_KEY" required:"true"`
+//		Debug    bool   `ignored:"true"`
+//	}
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.EnvconfigCompat("myapp"))
+//
+// This reads MYAPP_MAX_BYTES and MYAPP_API_KEY. As with envconfig, keys
+// default to the uppercased field name (split on word boundaries with
+// split_words), every exported field without ignored:"true" is read, and
+// fields are optional unless tagged required:"true". Fields that also
+// have an env tag use it instead, so you can retag gradually. Unlike
+// envconfig, nested structs aren't supported and the unprefixed key
+// isn't used as a fallback.
+func EnvconfigCompat(prefix string) DecodeOption {
+	return funcOption(func(o *options) {
+		o.EnvconfigCompat = true
+		o.EnvconfigPrefix = prefix
+	})
+}
+
+// fieldTag returns the env key and tag options for field, translating
+// tags from other libraries if a compatibility option is set.
+func (o options) fieldTag(field reflect.StructField) (string, tagOptions) {
+	tag, ok := field.Tag.Lookup("env")
+	if ok && o.Caarlos0Compat {
+		return caarlos0Tag(tag)
+	}
+	if ok || !o.EnvconfigCompat {
+		return parseTag(tag)
+	}
+	if !field.IsExported() || field.Tag.Get("ignored") == "true" {
+		return "", ""
+	}
+	key := field.Name
+	if field.Tag.Get("split_words") == "true" {
+		key = splitWords(key)
+	}
+	if name := field.Tag.Get("envconfig"); name != "" {
+		key = name
+	}
+	if o.EnvconfigPrefix != "" {
+		key = o.EnvconfigPrefix + "_" + key
+	}
+	_, hasDefault := o.fieldDefault(field)
+	if field.Tag.Get("required") == "true" && !hasDefault {
+		return strings.ToUpper(key), "required"
+	}
+	return strings.ToUpper(key), "optional"
+}
+
+// caarlos0Tag translates an env tag from github.com/caarlos0/env. Fields
+// are optional unless they're required, and notEmpty is treated like
+// required plus nonzero. Options we don't support, like file, are kept so
+// they're reported as invalid instead of silently ignored.
+func caarlos0Tag(tag string) (string, tagOptions) {
+	key, tagOpts := parseTag(tag)
+	var translated []string
+	required := false
+	for _, name := range tagOpts.names() {
+		switch name {
+		case "required":
+			required = true
+		case "notEmpty":
+			required = true
+			translated = append(translated, "nonzero")
+		default:
+			translated = append(translated, name)
+		}
+	}
+	if required {
+		translated = append(translated, "required")
+	} else {
+		translated = append(translated, "optional")
+	}
+	return key, tagOptions(strings.Join(translated, ","))
+}
+
+// fieldDefault returns the default value for field from its default tag,
+// or its envDefault tag with [Caarlos0Compat].
+func (o options) fieldDefault(field reflect.StructField) (string, bool) {
+	if value, ok := field.Tag.Lookup("default"); ok || !o.Caarlos0Compat {
+		return value, ok
+	}
+	return field.Tag.Lookup("envDefault")
+}
+
+// separator returns the string that slice values for field are split on:
+// its sep tag (or envSeparator with [Caarlos0Compat]), or a comma.
+func (o options) separator(field reflect.StructField) string {
+	if sep, ok := field.Tag.Lookup("sep"); ok {
+		return sep
+	}
+	if sep, ok := field.Tag.Lookup("envSeparator"); ok && o.Caarlos0Compat {
+		return sep
+	}
+	return ","
+}
+
+// These match how envconfig splits field names into words.
+var (
+	wordsRegexp   = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// splitWords turns a field name like "MaxBytes" into "Max_Bytes". Runs
+// of capitals are treated as acronyms, so "APIKey" is "API_Key".
+func splitWords(name string) string {
+	var words []string
+	for _, word := range wordsRegexp.FindAllString(name, -1) {
+		if m := acronymRegexp.FindStringSubmatch(word); m != nil {
+			words = append(words, m[1], m[2])
+		} else {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, "_")
+}
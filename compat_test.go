@@ -0,0 +1,66 @@
+package dotconfig_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+func TestEnvconfigCompat(t *testing.T) {
+	type envconfigConfig struct {
+		MaxBytes  int    `split_words:"true" default:"1024"`
+		APIKey    string `envconfig:"API_KEY" required:"true"`
+		HTTPPort  int    `split_words:"true"`
+		Region    string
+		Debug     bool   `ignored:"true"`
+		Retagged  string `env:"COMPAT_RETAGGED"`
+		unexposed string
+	}
+	r := strings.NewReader(`COMPAT_API_KEY=abc123
+COMPAT_HTTP_PORT=8080
+COMPAT_REGION=us-west-2
+COMPAT_DEBUG=true
+COMPAT_RETAGGED=yes`)
+	config, err := dotconfig.FromReader[envconfigConfig](r, dotconfig.EnvconfigCompat("compat"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := envconfigConfig{MaxBytes: 1024, APIKey: "abc123", HTTPPort: 8080, Region: "us-west-2", Retagged: "yes"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	type requiredConfig struct {
+		Token string `required:"true"`
+	}
+	_, err = dotconfig.FromReader[requiredConfig](strings.NewReader(""), dotconfig.EnvconfigCompat("compat"))
+	if err == nil || err.Error() != "value not present in env: COMPAT_TOKEN" {
+		t.Errorf("Expected missing COMPAT_TOKEN. Got %v.", err)
+	}
+}
+
+func TestCaarlos0Compat(t *testing.T) {
+	type caarlos0Config struct {
+		Home     string   `env:"CAARLOS0_HOME" envDefault:"/tmp"`
+		Hosts    []string `env:"CAARLOS0_HOSTS" envSeparator:":"`
+		Token    string   `env:"CAARLOS0_TOKEN,notEmpty"`
+		Optional string   `env:"CAARLOS0_OPTIONAL"`
+	}
+	r := strings.NewReader("CAARLOS0_HOSTS=a:b\nCAARLOS0_TOKEN=abc123")
+	config, err := dotconfig.FromReader[caarlos0Config](r, dotconfig.Caarlos0Compat)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := caarlos0Config{Home: "/tmp", Hosts: []string{"a", "b"}, Token: "abc123"}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	r = strings.NewReader("CAARLOS0_TOKEN=")
+	_, err = dotconfig.FromReader[caarlos0Config](r, dotconfig.Caarlos0Compat)
+	if err == nil || err.Error() != "invalid value: CAARLOS0_TOKEN: must be non-zero" {
+		t.Errorf("Expected empty token error. Got %v.", err)
+	}
+}
@@ -0,0 +1,130 @@
+package dotconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSpec is a cron schedule like "0 3 * * *". It's checked when the
+// config is loaded, so a typo in something like BACKUP_SCHEDULE fails at
+// startup instead of the job silently never running:
+//
+//	type AppConfig struct {
+//		BackupSchedule dotconfig.CronSpec `env:"BACKUP_SCHEDULE"`
+//	}
+//
+// The standard five fields (minute, hour, day of month, month, day of
+// week) are supported, with lists, ranges and steps like "*/15" or
+// "1-5". Months and weekdays can also be names like "jan" or "mon". The
+// descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight
+// and @hourly are accepted, as is "@every <duration>".
+//
+// CronSpec only validates the schedule. Pass it to whatever scheduler
+// you use to actually run jobs.
+type CronSpec string
+
+// cronField describes the allowed values for one field of a CronSpec.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	// Both 0 and 7 are Sunday.
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cronDescriptors = map[string]bool{
+	"@yearly":   true,
+	"@annually": true,
+	"@monthly":  true,
+	"@weekly":   true,
+	"@daily":    true,
+	"@midnight": true,
+	"@hourly":   true,
+}
+
+// Validate returns an error if c isn't a valid cron schedule.
+func (c CronSpec) Validate() error {
+	spec := strings.TrimSpace(string(c))
+	if strings.HasPrefix(spec, "@") {
+		if every, ok := strings.CutPrefix(spec, "@every "); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(every))
+			if err != nil {
+				return err
+			}
+			if d <= 0 {
+				return fmt.Errorf("@every needs a positive duration")
+			}
+			return nil
+		}
+		if !cronDescriptors[strings.ToLower(spec)] {
+			return fmt.Errorf("unknown cron descriptor %q", spec)
+		}
+		return nil
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected %v cron fields, got %v", len(cronFields), len(fields))
+	}
+	for i, field := range fields {
+		if err := cronFields[i].check(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check validates one comma-separated field like "1-5,*/10".
+func (f cronField) check(field string) error {
+	for _, item := range strings.Split(field, ",") {
+		rng, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid %v step %q", f.name, step)
+			}
+		}
+		if rng == "*" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(rng, "-")
+		start, err := f.value(lo)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		end, err := f.value(hi)
+		if err != nil {
+			return err
+		}
+		if start > end {
+			return fmt.Errorf("invalid %v range %q", f.name, rng)
+		}
+	}
+	return nil
+}
+
+// value parses a single number or name in the field's range.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			// Month names start at 1, weekday names at 0.
+			return i + f.min, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %v %q", f.name, s)
+	}
+	return n, nil
+}
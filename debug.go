@@ -0,0 +1,47 @@
+package dotconfig
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Config</title></head>
+<body>
+<table>
+<tr><th>Field</th><th>Key</th><th>Value</th><th>Origin</th><th>Description</th></tr>
+{{range .}}<tr><td>{{.Field}}</td><td>{{.Key}}</td><td>{{.Value}}</td><td>{{.Origin}}</td><td>{{.Desc}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// DebugHandler returns an [http.Handler] that renders the config returned
+// by get, as described by [Explain]. Secret fields are redacted. The
+// response is JSON unless the request asks for HTML with an Accept
+// header or a format=html query parameter. It's meant to be mounted
+// somewhere private for use during incidents. Pass the [Result] of the
+// load with [WithResult] so origins are what happened during the load,
+// not what the environment says when the request comes in:
+//
+//	conf, res, err := dotconfig.LoadWithResult[AppConfig](file)
+//	// ...
+//	store := dotconfig.NewStore(conf)
+//	mux.Handle("/debug/config", dotconfig.DebugHandler(store.Get, dotconfig.WithResult(res)))
+func DebugHandler[T any](get func() T, opts ...DecodeOption) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		infos := Explain(get(), opts...)
+		if r.URL.Query().Get("format") == "html" || strings.Contains(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			debugTemplate.Execute(w, infos)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(infos)
+	})
+}
@@ -0,0 +1,114 @@
+package dotconfig_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+type debugConfig struct {
+	Host     string `env:"DEBUG_HOST"`
+	Password string `env:"DEBUG_PASSWORD,secret"`
+	Port     int    `env:"DEBUG_PORT" default:"8080"`
+}
+
+func TestDebugHandler(t *testing.T) {
+	t.Setenv("DEBUG_HOST", "localhost")
+	t.Setenv("DEBUG_PASSWORD", "hunter2")
+	config, err := dotconfig.FromReader[debugConfig](strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	handler := dotconfig.DebugHandler(func() debugConfig { return config })
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+	if strings.Contains(rec.Body.String(), "hunter2") {
+		t.Fatalf("Secret leaked in output:\n%v", rec.Body.String())
+	}
+	var infos []dotconfig.FieldInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &infos); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []dotconfig.FieldInfo{
+		{Field: "Host", Key: "DEBUG_HOST", Value: "localhost", Origin: dotconfig.OriginEnv},
+		{Field: "Password", Key: "DEBUG_PASSWORD", Value: "***", Secret: true, Origin: dotconfig.OriginEnv},
+		{Field: "Port", Key: "DEBUG_PORT", Value: "8080", Origin: dotconfig.OriginDefault},
+	}
+	if len(infos) != len(expected) {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, infos)
+	}
+	for i := range expected {
+		if infos[i] != expected[i] {
+			t.Errorf("Expected:\n%#v\nGot:\n%#v", expected[i], infos[i])
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config?format=html", nil))
+	if !strings.Contains(rec.Header().Get("Content-Type"), "text/html") || !strings.Contains(rec.Body.String(), "DEBUG_HOST") {
+		t.Errorf("Expected HTML output. Got:\n%v", rec.Body.String())
+	}
+}
+
+func TestDebugHandlerWithResult(t *testing.T) {
+	type resultConfig struct {
+		Host     string `env:"DEBUG_RESULT_HOST"`
+		Password string `env:"DEBUG_RESULT_PASSWORD,secret,from=DEBUG_RESULT_PASSWORD_REF"`
+		Port     int    `env:"DEBUG_RESULT_PORT" default:"8080"`
+	}
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// With NoExport the values never reach the environment, so only the
+	// Result knows where they came from.
+	r := strings.NewReader("DEBUG_RESULT_HOST=localhost\nDEBUG_RESULT_PASSWORD_REF=file://" + secret)
+	config, res, err := dotconfig.LoadWithResult[resultConfig](r, dotconfig.NoExport)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	// Changes to the environment after the load don't change origins.
+	t.Setenv("DEBUG_RESULT_PORT", "9090")
+	handler := dotconfig.DebugHandler(func() resultConfig { return config }, dotconfig.WithResult(res))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+	var infos []dotconfig.FieldInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &infos); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []dotconfig.FieldInfo{
+		{Field: "Host", Key: "DEBUG_RESULT_HOST", Value: "localhost", Origin: dotconfig.OriginEnv},
+		{Field: "Password", Key: "DEBUG_RESULT_PASSWORD", Value: "***", Secret: true, Origin: dotconfig.OriginEnv},
+		{Field: "Port", Key: "DEBUG_RESULT_PORT", Value: "8080", Origin: dotconfig.OriginDefault},
+	}
+	if len(infos) != len(expected) {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, infos)
+	}
+	for i := range expected {
+		if infos[i] != expected[i] {
+			t.Errorf("Expected:\n%#v\nGot:\n%#v", expected[i], infos[i])
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	config := debugConfig{Host: "localhost", Password: "hunter2", Port: 8080}
+	b, err := dotconfig.MarshalJSON(config)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := `{"DEBUG_HOST":"localhost","DEBUG_PASSWORD":"***","DEBUG_PORT":"8080"}`
+	if string(b) != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, string(b))
+	}
+	if _, err := dotconfig.MarshalJSON("not a struct"); err != dotconfig.ErrConfigMustBeStruct {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrConfigMustBeStruct, err)
+	}
+}
@@ -0,0 +1,354 @@
+package dotconfig
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	locationType    = reflect.TypeOf((*time.Location)(nil))
+	mailAddressType = reflect.TypeOf(mail.Address{})
+	fileModeType    = reflect.TypeOf(fs.FileMode(0))
+	cronSpecType    = reflect.TypeOf(CronSpec(""))
+	durationType    = reflect.TypeOf(time.Duration(0))
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	setterType          = reflect.TypeOf((*Setter)(nil)).Elem()
+)
+
+// invalidValue returns an [ErrInvalidValue] error caused by err. Parse
+// errors usually quote the value they failed on, so the value is masked
+// for secret fields or when [RedactValuesInErrors] is set. That way a
+// typo in a token can't leak it into aggregated logs.
+func (o options) invalidValue(value string, err error, tagOpts tagOptions) *FieldError {
+	if value != "" && (o.RedactValuesInErrors || tagOpts.Contains("secret")) {
+		err = redactError(err)
+	}
+	return &FieldError{Err: ErrInvalidValue, Cause: err}
+}
+
+// redactError returns an error for err whose message can't contain the
+// value that failed to parse. Looking for the value in err's message
+// isn't enough, since parsers quote it with escapes, so the message is
+// rebuilt from what kind of error it is instead. err is still there for
+// errors.Is and errors.As.
+func redactError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return redactedError{msg: fmt.Sprintf("strconv.%v: parsing %v: %v", numErr.Func, redacted, numErr.Err), err: err}
+	}
+	return redactedError{msg: "can't parse " + redacted, err: err}
+}
+
+// redactedError is an error with its message replaced by one without
+// the value in it.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e redactedError) Error() string {
+	return e.msg
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+// decodeValue parses value and stores it in v using the options from the
+// field's env tag. Slices are split on sep, except []byte, which holds
+// the value as is. For backwards compatibility, bad bools and numbers
+// decode to zero unless o.strict is set, but never in slices. Errors
+// only have Err and Cause set, the caller fills in which field they're
+// for.
+func (o options) decodeValue(v reflect.Value, value string, tagOpts tagOptions, sep string) *FieldError {
+	// Pointer fields are allocated and decoded through, like
+	// encoding/json does, so *int and *uuid.UUID work the same as int
+	// and uuid.UUID. *time.Location is the one pointer type with a
+	// parser of its own.
+	for v.Kind() == reflect.Pointer && v.Type() != locationType {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	// Secrets decode like the type they hold, but with values redacted
+	// from errors.
+	if v.CanAddr() && isSecretType(v.Type()) {
+		inner := v.Addr().Interface().(secretField).valuePtr()
+		return o.decodeValue(inner, value, tagOptions(string(tagOpts)+",secret"), sep)
+	}
+	// Optional values decode like the type they hold and are set.
+	if v.CanAddr() && isOptionalType(v.Type()) {
+		opt := v.Addr().Interface().(optionalField)
+		opt.markSet()
+		return o.decodeValue(opt.optionalValue(), value, tagOpts, sep)
+	}
+	// Nullable database/sql types decode like their value and are Valid.
+	if isSQLNull(v.Type()) {
+		if err := o.decodeValue(v.Field(0), value, tagOpts, sep); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+	// Types that take full control of their own parsing.
+	if s, ok := setter(v); ok {
+		if err := s.SetFromEnv(value); err != nil {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		return nil
+	}
+	// Some types need special handling before we fall back to their kind.
+	switch v.Type() {
+	case locationType:
+		loc, err := time.LoadLocation(value)
+		if err != nil {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.Set(reflect.ValueOf(loc))
+		return nil
+	case mailAddressType:
+		addr, err := mail.ParseAddress(value)
+		if err != nil {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.Set(reflect.ValueOf(*addr))
+		return nil
+	case fileModeType:
+		// File modes are always octal, so UMASK=0027 is 0o027 and
+		// not 27. An explicit 0o prefix is fine too.
+		mode, err := strconv.ParseUint(strings.TrimPrefix(value, "0o"), 8, 32)
+		if err != nil {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.SetUint(mode)
+		return nil
+	case cronSpecType:
+		if err := CronSpec(value).Validate(); err != nil {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.SetString(value)
+		return nil
+	case durationType:
+		return o.decodeDuration(v, value, tagOpts)
+	}
+	// Types that know how to parse themselves, like decimal.Decimal from
+	// github.com/shopspring/decimal or net.IP.
+	if u, ok := textUnmarshaler(v); ok {
+		if err := u.UnmarshalText([]byte(value)); err != nil {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		return nil
+	}
+	return o.decodeKind(v, value, tagOpts, sep)
+}
+
+// decodeKind is the part of decodeValue that goes by v's kind, for
+// types that don't have a parser of their own.
+func (o options) decodeKind(v reflect.Value, value string, tagOpts tagOptions, sep string) *FieldError {
+	// Based on type, parse and set values. This borrows from encoding/json:
+	// https://cs.opensource.google/go/go/+/refs/tags/go1.23.1:src/encoding/json/decode.go;l=990
+	switch v.Kind() {
+	case reflect.Bool:
+		if o.ExtendedBools {
+			val, err := parseExtendedBool(value)
+			if err != nil {
+				return o.invalidValue(value, err, tagOpts)
+			}
+			v.SetBool(val)
+			return nil
+		}
+		val, err := strconv.ParseBool(value)
+		if err != nil && o.strict {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.SetBool(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// With the autobase tag option, the base is implied by the
+		// prefix: 0x1F, 0o755, 0b1010. It's opt-in because a leading
+		// zero would otherwise change the meaning of existing values.
+		if tagOpts.Contains("autobase") {
+			val, err := strconv.ParseInt(value, 0, v.Type().Bits())
+			if err != nil {
+				return o.invalidValue(value, err, tagOpts)
+			}
+			v.SetInt(val)
+			return nil
+		}
+		val, err := strconv.ParseInt(value, 10, 64)
+		if err != nil && o.strict {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.SetInt(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if tagOpts.Contains("autobase") {
+			val, err := strconv.ParseUint(value, 0, v.Type().Bits())
+			if err != nil {
+				return o.invalidValue(value, err, tagOpts)
+			}
+			v.SetUint(val)
+			return nil
+		}
+		val, err := strconv.ParseUint(value, 10, 64)
+		if err != nil && o.strict {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.SetUint(val)
+	case reflect.Float32, reflect.Float64:
+		val, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil && o.strict {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		v.SetFloat(val)
+	case reflect.String:
+		// Fields tagged path get ~ and $HOME expanded and are cleaned.
+		if tagOpts.Contains("path") {
+			path, err := o.decodePath(value, tagOpts)
+			if err != nil {
+				return o.invalidValue(value, err, tagOpts)
+			}
+			value = path
+		}
+		v.SetString(value)
+	case reflect.Slice:
+		return o.decodeSlice(v, value, sep, compileDecoder(v.Type().Elem(), tagOpts, sep))
+	default:
+		return &FieldError{Err: ErrUnsupportedFieldType, Cause: errors.New(v.Type().String())}
+	}
+	return nil
+}
+
+// decodeDuration decodes a [time.Duration], written like "1m30s". Plain
+// numbers are still nanoseconds, and anything else is zero unless
+// o.strict is set, which is how they decoded before durations were
+// handled here.
+func (o options) decodeDuration(v reflect.Value, value string, tagOpts tagOptions) *FieldError {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		n, nerr := strconv.ParseInt(value, 10, 64)
+		if nerr != nil && o.strict {
+			return o.invalidValue(value, err, tagOpts)
+		}
+		d = time.Duration(n)
+	}
+	v.SetInt(int64(d))
+	return nil
+}
+
+// decodeSlice splits value on sep and decodes each part into an element
+// of the slice v with elem.
+func (o options) decodeSlice(v reflect.Value, value, sep string, elem decoder) *FieldError {
+	// []byte is the value itself, not a list of numbers.
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		v.SetBytes([]byte(value))
+		return nil
+	}
+	if value == "" {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		return nil
+	}
+	// A zero in place of a bad element would be easy to miss, so
+	// bad elements are always errors.
+	o.strict = true
+	n := strings.Count(value, sep) + 1
+	if sep == "" {
+		// Like strings.Split, an empty sep splits after each UTF-8
+		// sequence.
+		n = utf8.RuneCountInString(value)
+	}
+	slice := reflect.MakeSlice(v.Type(), n, n)
+	for i := range n {
+		var part string
+		if sep == "" {
+			_, size := utf8.DecodeRuneInString(value)
+			part, value = value[:size], value[size:]
+		} else {
+			part, value, _ = strings.Cut(value, sep)
+		}
+		if err := elem(o, slice.Index(i), strings.TrimSpace(part)); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+// decoder decodes value into v. Plans pick one for each field ahead of
+// time with compileDecoder.
+type decoder func(o options, v reflect.Value, value string) *FieldError
+
+// compileDecoder returns the decoder for values of type t with the
+// given tag options and separator. Most types end up decoded by their
+// kind, so their decoders go straight to decodeKind instead of checking
+// for pointers, wrappers and parser interfaces on every load.
+func compileDecoder(t reflect.Type, tagOpts tagOptions, sep string) decoder {
+	switch {
+	case t == durationType:
+		return func(o options, v reflect.Value, value string) *FieldError {
+			return o.decodeDuration(v, value, tagOpts)
+		}
+	case !kindOnly(t):
+		return func(o options, v reflect.Value, value string) *FieldError {
+			return o.decodeValue(v, value, tagOpts, sep)
+		}
+	case t.Kind() == reflect.Slice:
+		elem := compileDecoder(t.Elem(), tagOpts, sep)
+		return func(o options, v reflect.Value, value string) *FieldError {
+			return o.decodeSlice(v, value, sep, elem)
+		}
+	}
+	return func(o options, v reflect.Value, value string) *FieldError {
+		return o.decodeKind(v, value, tagOpts, sep)
+	}
+}
+
+// kindOnly reports whether decodeValue would decode values of type t by
+// their kind alone.
+func kindOnly(t reflect.Type) bool {
+	switch t {
+	case locationType, mailAddressType, fileModeType, cronSpecType, durationType:
+		return false
+	}
+	pt := reflect.PointerTo(t)
+	return t.Kind() != reflect.Pointer && !isSecretType(t) && !isOptionalType(t) && !isSQLNull(t) &&
+		!pt.Implements(setterType) && !pt.Implements(textUnmarshalerType)
+}
+
+// textUnmarshaler returns v as an [encoding.TextUnmarshaler] if it or
+// its pointer implements it. Addressable values go through their
+// pointer, whose method set covers both pointer receivers (uuid.UUID)
+// and value receivers (net.IP), so v is always the one written to.
+func textUnmarshaler(v reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if v.CanAddr() {
+		u, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+		return u, ok
+	}
+	// Without an address only value receivers can be called, which is
+	// still useful for reference types like maps.
+	if v.Type().Implements(textUnmarshalerType) {
+		return v.Interface().(encoding.TextUnmarshaler), true
+	}
+	return nil, false
+}
+
+// parseExtendedBool is like [strconv.ParseBool] but also accepts
+// yes/no, on/off, and enabled/disabled in any case, since that's what
+// people tend to write in env files.
+func parseExtendedBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "on", "enabled":
+		return true, nil
+	case "no", "n", "off", "disabled":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
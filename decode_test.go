@@ -0,0 +1,604 @@
+package dotconfig_test
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+func TestDecodeLocation(t *testing.T) {
+	type locationConfig struct {
+		TZ *time.Location `env:"DECODE_TZ"`
+	}
+	config, err := dotconfig.FromReader[locationConfig](strings.NewReader("DECODE_TZ=America/Los_Angeles"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.TZ == nil || config.TZ.String() != "America/Los_Angeles" {
+		t.Errorf("Expected America/Los_Angeles. Got %v.", config.TZ)
+	}
+
+	_, err = dotconfig.FromReader[locationConfig](strings.NewReader("DECODE_TZ=Mars/Olympus_Mons"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeMailAddress(t *testing.T) {
+	type mailConfig struct {
+		SupportEmail mail.Address `env:"DECODE_SUPPORT_EMAIL"`
+	}
+	r := strings.NewReader(`DECODE_SUPPORT_EMAIL="Support <support@example.com>"`)
+	config, err := dotconfig.FromReader[mailConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := mail.Address{Name: "Support", Address: "support@example.com"}
+	if config.SupportEmail != expected {
+		t.Errorf("Expected %v. Got %v.", expected, config.SupportEmail)
+	}
+
+	_, err = dotconfig.FromReader[mailConfig](strings.NewReader("DECODE_SUPPORT_EMAIL=not an email"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeFileMode(t *testing.T) {
+	type fileModeConfig struct {
+		Umask    fs.FileMode `env:"DECODE_UMASK"`
+		FileMode os.FileMode `env:"DECODE_FILE_MODE"`
+	}
+	r := strings.NewReader("DECODE_UMASK=0027\nDECODE_FILE_MODE=0o644")
+	config, err := dotconfig.FromReader[fileModeConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Umask != 0o027 || config.FileMode != 0o644 {
+		t.Errorf("Expected 0027 and 0644. Got %o and %o.", config.Umask, config.FileMode)
+	}
+
+	_, err = dotconfig.FromReader[fileModeConfig](strings.NewReader("DECODE_UMASK=0089"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeDuration(t *testing.T) {
+	type durationConfig struct {
+		Timeout  time.Duration `env:"DECODE_TIMEOUT"`
+		Interval time.Duration `env:"DECODE_INTERVAL"`
+	}
+	r := strings.NewReader("DECODE_TIMEOUT=1m30s\nDECODE_INTERVAL=1000")
+	config, err := dotconfig.FromReader[durationConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Timeout != 90*time.Second || config.Interval != 1000 {
+		t.Errorf("Expected 1m30s and 1µs. Got %v and %v.", config.Timeout, config.Interval)
+	}
+
+	// Like other numbers, bad durations are zero, but ValidateStruct
+	// catches them in defaults.
+	config, err = dotconfig.FromReader[durationConfig](strings.NewReader("DECODE_TIMEOUT=soon\nDECODE_INTERVAL=1s"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Timeout != 0 || config.Interval != time.Second {
+		t.Errorf("Expected 0s and 1s. Got %v and %v.", config.Timeout, config.Interval)
+	}
+	type badDefaultConfig struct {
+		Timeout time.Duration `env:"DECODE_BAD_TIMEOUT" default:"soon"`
+	}
+	if err := dotconfig.ValidateStruct[badDefaultConfig](); !errors.Is(err, dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeCronSpec(t *testing.T) {
+	type cronConfig struct {
+		Backup  dotconfig.CronSpec `env:"DECODE_BACKUP_SCHEDULE"`
+		Report  dotconfig.CronSpec `env:"DECODE_REPORT_SCHEDULE"`
+		Cleanup dotconfig.CronSpec `env:"DECODE_CLEANUP_SCHEDULE"`
+	}
+	r := strings.NewReader("DECODE_BACKUP_SCHEDULE=*/15 0-6,22 * jan-mar mon-fri\nDECODE_REPORT_SCHEDULE=@daily\nDECODE_CLEANUP_SCHEDULE=@every 1h30m")
+	config, err := dotconfig.FromReader[cronConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := cronConfig{Backup: "*/15 0-6,22 * jan-mar mon-fri", Report: "@daily", Cleanup: "@every 1h30m"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	for _, spec := range []string{"0 3 * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "@fortnightly", "@every never"} {
+		_, err = dotconfig.FromReader[cronConfig](strings.NewReader("DECODE_BACKUP_SCHEDULE=" + spec))
+		if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+			t.Errorf("Expected error for %q: %v. Got: %v.", spec, dotconfig.ErrInvalidValue, err)
+		}
+	}
+}
+
+func TestDecodeHostPort(t *testing.T) {
+	type hostPortConfig struct {
+		Listen dotconfig.HostPort   `env:"DECODE_LISTEN_ADDR"`
+		DB     dotconfig.HostPort   `env:"DECODE_DB_ADDR"`
+		Peers  []dotconfig.HostPort `env:"DECODE_PEERS"`
+	}
+	r := strings.NewReader("DECODE_LISTEN_ADDR=:8080\nDECODE_DB_ADDR=db.internal:5432\nDECODE_PEERS=[::1]:7000,10.0.0.2:7000")
+	config, err := dotconfig.FromReader[hostPortConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := hostPortConfig{
+		Listen: dotconfig.HostPort{Port: 8080},
+		DB:     dotconfig.HostPort{Host: "db.internal", Port: 5432},
+		Peers:  []dotconfig.HostPort{{Host: "::1", Port: 7000}, {Host: "10.0.0.2", Port: 7000}},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	if config.Peers[0].String() != "[::1]:7000" {
+		t.Errorf("Expected %q. Got %q.", "[::1]:7000", config.Peers[0].String())
+	}
+
+	for _, value := range []string{"db.internal", "db.internal:http", "db.internal:70000", "::1:8080"} {
+		r := strings.NewReader("DECODE_LISTEN_ADDR=" + value + "\nDECODE_DB_ADDR=:1\nDECODE_PEERS=")
+		_, err := dotconfig.FromReader[hostPortConfig](r)
+		if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+			t.Errorf("Expected error for %q: %v. Got: %v.", value, dotconfig.ErrInvalidValue, err)
+		}
+	}
+}
+
+func TestDecodeTextUnmarshaler(t *testing.T) {
+	type priceConfig struct {
+		Price *big.Rat `env:"DECODE_PRICE"`
+		Host  net.IP   `env:"DECODE_HOST_IP"`
+	}
+	r := strings.NewReader("DECODE_PRICE=19.99\nDECODE_HOST_IP=10.0.0.1")
+	config, err := dotconfig.FromReader[priceConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Price == nil || config.Price.Cmp(big.NewRat(1999, 100)) != 0 {
+		t.Errorf("Expected 1999/100. Got %v.", config.Price)
+	}
+	if !config.Host.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Expected 10.0.0.1. Got %v.", config.Host)
+	}
+
+	_, err = dotconfig.FromReader[priceConfig](strings.NewReader("DECODE_PRICE=$19.99"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+// hexID is like uuid.UUID: an array with a pointer receiver
+// UnmarshalText and a value receiver MarshalText.
+type hexID [4]byte
+
+func (id *hexID) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil || len(b) != len(id) {
+		return fmt.Errorf("bad id %q", text)
+	}
+	copy(id[:], b)
+	return nil
+}
+
+func (id hexID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%x", id[:])), nil
+}
+
+func TestDecodeReceivers(t *testing.T) {
+	type receiverConfig struct {
+		ID      hexID     `env:"DECODE_ID"`
+		IDPtr   *hexID    `env:"DECODE_ID_PTR"`
+		IDs     []*hexID  `env:"DECODE_IDS"`
+		IPPtr   *net.IP   `env:"DECODE_IP_PTR"`
+		Price   **big.Rat `env:"DECODE_PRICE_PTR"`
+		Retries *int      `env:"DECODE_RETRIES"`
+	}
+	r := strings.NewReader("DECODE_ID=deadbeef\nDECODE_ID_PTR=01020304\nDECODE_IDS=0a0b0c0d,ffffffff\n" +
+		"DECODE_IP_PTR=10.0.0.1\nDECODE_PRICE_PTR=1/3\nDECODE_RETRIES=3")
+	config, err := dotconfig.FromReader[receiverConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.ID != (hexID{0xde, 0xad, 0xbe, 0xef}) || config.IDPtr == nil || *config.IDPtr != (hexID{1, 2, 3, 4}) {
+		t.Errorf("Expected deadbeef and 01020304. Got %v and %v.", config.ID, config.IDPtr)
+	}
+	if len(config.IDs) != 2 || *config.IDs[1] != (hexID{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("Expected two IDs. Got %v.", config.IDs)
+	}
+	if config.IPPtr == nil || !config.IPPtr.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Expected 10.0.0.1. Got %v.", config.IPPtr)
+	}
+	if config.Price == nil || (*config.Price).Cmp(big.NewRat(1, 3)) != 0 {
+		t.Errorf("Expected 1/3. Got %v.", config.Price)
+	}
+	if config.Retries == nil || *config.Retries != 3 {
+		t.Errorf("Expected 3. Got %v.", config.Retries)
+	}
+
+	var buf strings.Builder
+	if err := dotconfig.WriteShellConfig(&buf, config); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	for _, line := range []string{"DECODE_ID='deadbeef'", "DECODE_ID_PTR='01020304'", "DECODE_IDS='0a0b0c0d,ffffffff'", "DECODE_PRICE_PTR='1/3'", "DECODE_RETRIES='3'"} {
+		if !strings.Contains(buf.String(), "export "+line+"\n") {
+			t.Errorf("Expected %v. Got:\n%v", line, buf.String())
+		}
+	}
+}
+
+// logLevel is a Setter that also has an UnmarshalText, which should be
+// ignored in favor of SetFromEnv.
+type logLevel int
+
+func (l *logLevel) SetFromEnv(value string) error {
+	switch strings.ToLower(value) {
+	case "debug":
+		*l = -4
+	case "", "info":
+		*l = 0
+	case "warn":
+		*l = 4
+	default:
+		return fmt.Errorf("unknown log level %q", value)
+	}
+	return nil
+}
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	return errors.New("UnmarshalText shouldn't be called")
+}
+
+func TestDecodeSetter(t *testing.T) {
+	type setterConfig struct {
+		Level  logLevel   `env:"DECODE_LOG_LEVEL"`
+		Levels []logLevel `env:"DECODE_LOG_LEVELS"`
+	}
+	r := strings.NewReader("DECODE_LOG_LEVEL=DEBUG\nDECODE_LOG_LEVELS=info,warn")
+	config, err := dotconfig.FromReader[setterConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := setterConfig{Level: -4, Levels: []logLevel{0, 4}}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	_, err = dotconfig.FromReader[setterConfig](strings.NewReader("DECODE_LOG_LEVEL=loud\nDECODE_LOG_LEVELS=info"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) || !strings.Contains(err.Error(), "unknown log level") {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeSlices(t *testing.T) {
+	type sliceConfig struct {
+		Hosts []string `env:"DECODE_HOSTS"`
+		Ports []int    `env:"DECODE_PORTS" sep:";"`
+		Empty []string `env:"DECODE_EMPTY_LIST,optional"`
+	}
+	r := strings.NewReader("DECODE_HOSTS=a.example.com, b.example.com\nDECODE_PORTS=80;443")
+	config, err := dotconfig.FromReader[sliceConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := sliceConfig{Hosts: []string{"a.example.com", "b.example.com"}, Ports: []int{80, 443}}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestDecodeSliceElements(t *testing.T) {
+	type bytesConfig struct {
+		Key []byte `env:"DECODE_BYTES_KEY"`
+	}
+	config, err := dotconfig.FromReader[bytesConfig](strings.NewReader("DECODE_BYTES_KEY=a,b c"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if string(config.Key) != "a,b c" {
+		t.Errorf("Expected %v. Got %v.", "a,b c", string(config.Key))
+	}
+
+	// An empty sep splits after each character, like strings.Split.
+	type charsConfig struct {
+		Chars []string `env:"DECODE_CHARS" sep:""`
+	}
+	chars, err := dotconfig.FromReader[charsConfig](strings.NewReader("DECODE_CHARS=aé,"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if expected := []string{"a", "é", ","}; !reflect.DeepEqual(chars.Chars, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, chars.Chars)
+	}
+
+	// Bad elements are errors even though bad numbers aren't.
+	type portsConfig struct {
+		Ports []int `env:"DECODE_BAD_PORTS"`
+	}
+	_, err = dotconfig.FromReader[portsConfig](strings.NewReader("DECODE_BAD_PORTS=80,http"))
+	if !errors.Is(err, dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeAutobase(t *testing.T) {
+	type autobaseConfig struct {
+		Hex     int    `env:"DECODE_HEX,autobase"`
+		Octal   uint32 `env:"DECODE_OCTAL,autobase"`
+		Binary  int8   `env:"DECODE_BINARY,autobase"`
+		Decimal int    `env:"DECODE_DECIMAL"`
+	}
+	r := strings.NewReader("DECODE_HEX=0x1F\nDECODE_OCTAL=0o755\nDECODE_BINARY=0b1010\nDECODE_DECIMAL=0755")
+	config, err := dotconfig.FromReader[autobaseConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := autobaseConfig{Hex: 0x1F, Octal: 0o755, Binary: 0b1010, Decimal: 755}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	_, err = dotconfig.FromReader[autobaseConfig](strings.NewReader("DECODE_BINARY=0xFFFF"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeExtendedBools(t *testing.T) {
+	type boolConfig struct {
+		Yes      bool `env:"DECODE_YES"`
+		Off      bool `env:"DECODE_OFF"`
+		Enabled  bool `env:"DECODE_ENABLED"`
+		Standard bool `env:"DECODE_STANDARD"`
+	}
+	r := strings.NewReader("DECODE_YES=yes\nDECODE_OFF=OFF\nDECODE_ENABLED=Enabled\nDECODE_STANDARD=true")
+	config, err := dotconfig.FromReader[boolConfig](r, dotconfig.ExtendedBools)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := boolConfig{Yes: true, Off: false, Enabled: true, Standard: true}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	_, err = dotconfig.FromReader[boolConfig](strings.NewReader("DECODE_YES=maybe"), dotconfig.ExtendedBools)
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+}
+
+func TestDecodeMap(t *testing.T) {
+	r := strings.NewReader("MAPTEST_ONE=1\nMAPTEST_TWO=two\nOTHER_MAPTEST=3")
+	strs, err := dotconfig.FromReader[map[string]string](r, dotconfig.WithPrefix("MAPTEST_"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := map[string]string{"MAPTEST_ONE": "1", "MAPTEST_TWO": "two"}
+	if !reflect.DeepEqual(strs, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, strs)
+	}
+
+	anys, err := dotconfig.FromReader[map[string]any](strings.NewReader(""), dotconfig.WithPrefix("MAPTEST_"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if anys["MAPTEST_TWO"] != "two" || len(anys) != 2 {
+		t.Errorf("Unexpected map: %#v", anys)
+	}
+
+	// Other maps still aren't supported.
+	_, err = dotconfig.FromReader[map[string]int](strings.NewReader(""))
+	if !errors.Is(err, dotconfig.ErrConfigMustBeStruct) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrConfigMustBeStruct, err)
+	}
+}
+
+func TestRedactValuesInErrors(t *testing.T) {
+	type redactConfig struct {
+		Token   int `env:"REDACT_TOKEN,secret,autobase"`
+		Retries int `env:"REDACT_RETRIES,autobase"`
+	}
+	r := strings.NewReader("REDACT_TOKEN=0xSECRET\nREDACT_RETRIES=0xNOTSECRET")
+	_, err := dotconfig.FromReader[redactConfig](r)
+	if len(dotconfig.Errors(err)) != 2 || strings.Contains(err.Error(), "0xSECRET") || !strings.Contains(err.Error(), "0xNOTSECRET") {
+		t.Errorf("Expected only secret value to be redacted. Got %v.", err)
+	}
+	_, err = dotconfig.FromReader[redactConfig](strings.NewReader(""), dotconfig.RedactValuesInErrors)
+	if err == nil || strings.Contains(err.Error(), "0xNOTSECRET") {
+		t.Errorf("Expected all values to be redacted. Got %v.", err)
+	}
+
+	// Parse errors quote the value with escapes, so a secret with a
+	// quote in it doesn't appear verbatim but still mustn't leak.
+	type quotedConfig struct {
+		Token     int       `env:"REDACT_QUOTED_TOKEN,secret,autobase"`
+		ExpiresAt time.Time `env:"REDACT_QUOTED_EXPIRES_AT,secret"`
+	}
+	r = strings.NewReader(`REDACT_QUOTED_TOKEN=tok"en-s3cr3t
+REDACT_QUOTED_EXPIRES_AT=tok"en-s3cr3t`)
+	_, err = dotconfig.FromReader[quotedConfig](r)
+	errs := dotconfig.Errors(err)
+	if len(errs) != 2 || strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("Expected secrets to be redacted. Got %v.", err)
+	}
+	var fieldErr *dotconfig.FieldError
+	if !errors.As(errs[0], &fieldErr) || !errors.Is(fieldErr.Cause, strconv.ErrSyntax) {
+		t.Errorf("Expected cause: %v. Got: %v.", strconv.ErrSyntax, errs[0])
+	}
+}
+
+func TestDecodeRuntimeValues(t *testing.T) {
+	type runtimeConfig struct {
+		Hostname  string    `env:",hostname"`
+		PID       int       `env:",pid"`
+		StartedAt time.Time `env:",now"`
+	}
+	before := time.Now().Add(-time.Second)
+	config, err := dotconfig.FromReader[runtimeConfig](strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	hostname, _ := os.Hostname()
+	if config.Hostname != hostname {
+		t.Errorf("Expected hostname %v. Got %v.", hostname, config.Hostname)
+	}
+	if config.PID != os.Getpid() {
+		t.Errorf("Expected pid %v. Got %v.", os.Getpid(), config.PID)
+	}
+	if config.StartedAt.Before(before) || config.StartedAt.After(time.Now()) {
+		t.Errorf("Expected now. Got %v.", config.StartedAt)
+	}
+}
+
+func TestDecodeSQLNull(t *testing.T) {
+	type nullConfig struct {
+		Name    sql.NullString  `env:"SQL_NULL_NAME"`
+		Port    sql.NullInt64   `env:"SQL_NULL_PORT"`
+		Debug   sql.NullBool    `env:"SQL_NULL_DEBUG"`
+		Ratio   sql.NullFloat64 `env:"SQL_NULL_RATIO"`
+		Timeout sql.Null[int32] `env:"SQL_NULL_TIMEOUT"`
+		Empty   sql.NullString  `env:"SQL_NULL_EMPTY"`
+	}
+	r := strings.NewReader("SQL_NULL_NAME=app\nSQL_NULL_PORT=5432\nSQL_NULL_TIMEOUT=30\nSQL_NULL_EMPTY=")
+	config, err := dotconfig.FromReader[nullConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := nullConfig{
+		Name:    sql.NullString{String: "app", Valid: true},
+		Port:    sql.NullInt64{Int64: 5432, Valid: true},
+		Timeout: sql.Null[int32]{V: 30, Valid: true},
+	}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	type requiredNullConfig struct {
+		Port sql.NullInt64 `env:"SQL_NULL_REQUIRED_PORT,required"`
+	}
+	if _, err := dotconfig.FromReader[requiredNullConfig](strings.NewReader("")); !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected %v. Got %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+}
+
+func TestDecodeOptional(t *testing.T) {
+	type optionalConfig struct {
+		MaxConns dotconfig.Optional[int]           `env:"OPTIONAL_MAX_CONNS"`
+		Timeout  dotconfig.Optional[time.Duration] `env:"OPTIONAL_TIMEOUT"`
+		Empty    dotconfig.Optional[int]           `env:"OPTIONAL_EMPTY"`
+		Default  dotconfig.Optional[string]        `env:"OPTIONAL_DEFAULT" default:"x"`
+	}
+	r := strings.NewReader("OPTIONAL_MAX_CONNS=0\nOPTIONAL_EMPTY=")
+	config, err := dotconfig.FromReader[optionalConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := optionalConfig{
+		MaxConns: dotconfig.Some(0),
+		Empty:    dotconfig.Some(0),
+		Default:  dotconfig.Some("x"),
+	}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	if config.Timeout.IsSet() || config.Timeout.Or(time.Second) != time.Second {
+		t.Errorf("Expected Timeout to be unset. Got %#v.", config.Timeout)
+	}
+	if !config.MaxConns.IsSet() || config.MaxConns.Or(10) != 0 {
+		t.Errorf("Expected MaxConns to be set to 0. Got %#v.", config.MaxConns)
+	}
+
+	type requiredOptionalConfig struct {
+		MaxConns dotconfig.Optional[int] `env:"OPTIONAL_REQUIRED,required"`
+	}
+	if _, err := dotconfig.FromReader[requiredOptionalConfig](strings.NewReader("")); !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected %v. Got %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+}
+
+func TestDecodePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	type pathConfig struct {
+		Config  string   `env:"PATH_CONFIG,path"`
+		Cache   string   `env:"PATH_CACHE,path"`
+		Search  []string `env:"PATH_SEARCH,path" sep:":"`
+		Other   string   `env:"PATH_OTHER,path"`
+		Exists  string   `env:"PATH_EXISTS,path,mustexist"`
+		Default string   `env:"PATH_DEFAULT,path" default:"~/.app/"`
+	}
+	r := strings.NewReader(`PATH_CONFIG=~/.config/app/../app.toml
+PATH_CACHE=${HOME}/cache//app
+PATH_SEARCH=$HOME/bin:/usr/local/bin/
+PATH_OTHER=$HOMEPATH/x
+PATH_EXISTS=~`)
+	config, err := dotconfig.FromReader[pathConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := pathConfig{
+		Config:  filepath.Join(home, ".config", "app.toml"),
+		Cache:   filepath.Join(home, "cache", "app"),
+		Search:  []string{filepath.Join(home, "bin"), "/usr/local/bin"},
+		Other:   "$HOMEPATH/x",
+		Exists:  home,
+		Default: filepath.Join(home, ".app"),
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	type missingConfig struct {
+		Dir string `env:"PATH_MISSING,path,mustexist"`
+	}
+	r = strings.NewReader("PATH_MISSING=~/does-not-exist")
+	_, err = dotconfig.FromReader[missingConfig](r)
+	var fieldErr *dotconfig.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Err != dotconfig.ErrInvalidValue || !errors.Is(fieldErr.Cause, fs.ErrNotExist) {
+		t.Errorf("Expected %v caused by %v. Got %v.", dotconfig.ErrInvalidValue, fs.ErrNotExist, err)
+	}
+
+	// HOME comes from the environment being decoded, not the process.
+	type environConfig struct {
+		Dir string `env:"PATH_ENVIRON,path"`
+	}
+	environ := map[string]string{"HOME": "/srv/app"}
+	envConfig, err := dotconfig.FromReader[environConfig](strings.NewReader("PATH_ENVIRON=~/data"), dotconfig.WithEnviron(environ))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if expected := filepath.Join("/srv/app", "data"); envConfig.Dir != expected {
+		t.Errorf("Expected %v. Got %v.", expected, envConfig.Dir)
+	}
+	_, err = dotconfig.FromReader[environConfig](strings.NewReader("PATH_ENVIRON=~/data"), dotconfig.WithEnviron(map[string]string{}))
+	if !errors.Is(err, dotconfig.ErrInvalidValue) {
+		t.Errorf("Expected %v. Got %v.", dotconfig.ErrInvalidValue, err)
+	}
+
+	type badTagConfig struct {
+		Dir string `env:"PATH_BAD_TAG,mustexist"`
+	}
+	if _, err := dotconfig.FromReader[badTagConfig](strings.NewReader("PATH_BAD_TAG=/")); !errors.Is(err, dotconfig.ErrInvalidTag) {
+		t.Errorf("Expected %v. Got %v.", dotconfig.ErrInvalidTag, err)
+	}
+}
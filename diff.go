@@ -0,0 +1,71 @@
+package dotconfig
+
+import "reflect"
+
+// Changes is a list of struct field names whose values differ between
+// two configs. See [Diff].
+type Changes []string
+
+// Contains reports whether the field called name changed.
+func (c Changes) Contains(name string) bool {
+	for _, field := range c {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff compares the exported top-level fields of old and new and
+// returns the names of those that differ, in struct field order. Nested
+// structs are compared as a whole, so a change to any DB setting in
+//
+//	type AppConfig struct {
+//		DB DBConfig
+//		// ...
+//	}
+//
+// is reported as "DB". [Lazy] fields are skipped, since their values
+// aren't known until Get. If T is not a struct, Diff returns nil.
+func Diff[T any](old, new T) Changes {
+	ov := reflect.ValueOf(&old).Elem()
+	nv := reflect.ValueOf(&new).Elem()
+	if ov.Kind() != reflect.Struct {
+		return nil
+	}
+	var changes Changes
+	for i := 0; i < ov.NumField(); i++ {
+		field := ov.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(withoutLazy(ov.Field(i)), withoutLazy(nv.Field(i))) {
+			changes = append(changes, field.Name)
+		}
+	}
+	return changes
+}
+
+// withoutLazy returns a copy of v with any [Lazy] fields zeroed, since
+// each decode binds them to a new lookup function.
+func withoutLazy(v reflect.Value) any {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	clearLazy(c)
+	return c.Interface()
+}
+
+func clearLazy(v reflect.Value) {
+	if _, ok := v.Addr().Interface().(lazyField); ok {
+		v.SetZero()
+		return
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			clearLazy(v.Field(i))
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package dotconfig_test
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/DeanPDX/dotconfig"
 )
@@ -118,6 +123,156 @@ func TestFromFileNameNoFile(t *testing.T) {
 	}
 }
 
+func TestFromBase64Env(t *testing.T) {
+	type bundleConfig struct {
+		Cert string `env:"BUNDLE_CERT"`
+		Port int    `env:"BUNDLE_PORT"`
+	}
+	env := "BUNDLE_CERT=\"-----BEGIN CERTIFICATE-----\\nMIIB\\n-----END CERTIFICATE-----\"\nBUNDLE_PORT=8080\n"
+	encoded := base64.StdEncoding.EncodeToString([]byte(env))
+	// Wrapped output, like base64 without -w0, is fine.
+	t.Setenv("BUNDLE_B64", encoded[:20]+"\n"+encoded[20:])
+	config, err := dotconfig.FromBase64Env[bundleConfig]("BUNDLE_B64")
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := bundleConfig{Cert: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----", Port: 8080}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	t.Setenv("BUNDLE_B64", "not base64!")
+	if _, err := dotconfig.FromBase64Env[bundleConfig]("BUNDLE_B64"); err == nil || !strings.HasPrefix(err.Error(), "decoding BUNDLE_B64:") {
+		t.Errorf("Expected decoding error. Got %v.", err)
+	}
+	if _, err := dotconfig.FromBase64Env[bundleConfig]("BUNDLE_MISSING", dotconfig.ReturnFileIOErrors); !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected %v. Got %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+}
+
+func TestFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't checked on Windows")
+	}
+	type permsConfig struct {
+		Token string `env:"PERMS_TOKEN"`
+	}
+	name := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(name, []byte("PERMS_TOKEN=secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(name, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var warnings []dotconfig.Warning
+	_, err := dotconfig.FromFileName[permsConfig](name, dotconfig.CheckPermissions, dotconfig.OnWarning(func(w dotconfig.Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != dotconfig.WarnLoosePermissions {
+		t.Errorf("Expected a loose permissions warning. Got %v.", warnings)
+	}
+	_, err = dotconfig.FromFileName[permsConfig](name, dotconfig.StrictPermissions)
+	if !errors.Is(err, dotconfig.ErrLoosePermissions) {
+		t.Errorf("Expected %v. Got %v.", dotconfig.ErrLoosePermissions, err)
+	}
+	if err := os.Chmod(name, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dotconfig.FromFileName[permsConfig](name, dotconfig.StrictPermissions); err != nil {
+		t.Errorf("Didn't expect error. Got %v.", err)
+	}
+}
+
+func TestRemoveAfterLoad(t *testing.T) {
+	type removeConfig struct {
+		Token string `env:"REMOVE_TOKEN"`
+	}
+	name := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(name, []byte("REMOVE_TOKEN=secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := dotconfig.FromFileName[removeConfig](name, dotconfig.RemoveAfterLoad)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Token != "secret" {
+		t.Errorf("Expected secret. Got %q.", config.Token)
+	}
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected file to be removed. Got %v.", err)
+	}
+
+	// Failed loads leave the file alone so it can be fixed.
+	if err := os.WriteFile(name, []byte("REMOVE_OTHER=value"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("REMOVE_TOKEN")
+	if _, err := dotconfig.FromFileName[removeConfig](name, dotconfig.RemoveAfterLoad); err == nil {
+		t.Fatal("Expected error for missing key.")
+	}
+	if _, err := os.Stat(name); err != nil {
+		t.Errorf("Expected file to still exist. Got %v.", err)
+	}
+}
+
+func TestCachedLoader(t *testing.T) {
+	type cachedConfig struct {
+		Region string `env:"CACHED_REGION"`
+	}
+	name := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(name, []byte("CACHED_REGION=us-west-2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CACHED_REGION", "")
+	loader := dotconfig.NewCachedLoader[cachedConfig](name)
+	config, err := loader.Load()
+	if err != nil || config.Region != "us-west-2" {
+		t.Fatalf("Expected us-west-2. Got %q, %v.", config.Region, err)
+	}
+	// The file hasn't changed, so it isn't read or decoded again.
+	os.Setenv("CACHED_REGION", "changed")
+	if config, _ = loader.Load(); config.Region != "us-west-2" {
+		t.Errorf("Expected cached us-west-2. Got %q.", config.Region)
+	}
+	if err := os.WriteFile(name, []byte("CACHED_REGION=eu-central-1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if config, _ = loader.Load(); config.Region != "eu-central-1" {
+		t.Errorf("Expected eu-central-1. Got %q.", config.Region)
+	}
+}
+
+func TestFromFileNameStdin(t *testing.T) {
+	type stdinConfig struct {
+		Token string `env:"STDIN_TOKEN"`
+	}
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	if _, err := stdin.WriteString("STDIN_TOKEN=abc123\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = original }()
+
+	config, err := dotconfig.FromFileName[stdinConfig]("-")
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Token != "abc123" {
+		t.Errorf("Expected abc123. Got %v.", config.Token)
+	}
+}
+
 const errTestStr = `MAX_BYTES_PER_REQUEST='1024'
 # You can do single quotes or not.
 API_VERSION=1.19
@@ -251,3 +406,944 @@ func ExampleErrors() {
 	// Unsupported type: unsupported field type: complex128
 	// Missing struct tag: missing struct tag on field: WelcomeMessage
 }
+
+func TestLoadWithResult(t *testing.T) {
+	type resultConfig struct {
+		Host    string `env:"RESULT_HOST"`
+		Port    int    `env:"RESULT_PORT" default:"8080"`
+		Verbose bool   `env:"RESULT_VERBOSE,optional"`
+	}
+	r := strings.NewReader(`RESULT_HOST=localhost
+THIS LINE IS MALFORMED`)
+	config, res, err := dotconfig.LoadWithResult[resultConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := resultConfig{Host: "localhost", Port: 8080}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	if !reflect.DeepEqual(res.KeysSet, []string{"RESULT_HOST"}) {
+		t.Errorf("Unexpected keys set: %v", res.KeysSet)
+	}
+	if !reflect.DeepEqual(res.Defaults, []string{"Port"}) {
+		t.Errorf("Unexpected defaults: %v", res.Defaults)
+	}
+	if !reflect.DeepEqual(res.Skipped, []string{"Verbose"}) {
+		t.Errorf("Unexpected skipped fields: %v", res.Skipped)
+	}
+	if len(res.Warnings) != 2 || res.Warnings[0].Kind != dotconfig.WarnMalformedLine || res.Warnings[1].Kind != dotconfig.WarnDefaultApplied {
+		t.Errorf("Expected malformed line and default warnings. Got: %v", res.Warnings)
+	}
+}
+
+func TestLoadThenBind(t *testing.T) {
+	type bindConfig struct {
+		Host string `env:"BIND_HOST"`
+		Port int    `env:"BIND_PORT"`
+	}
+	res, err := dotconfig.Load(strings.NewReader("BIND_HOST=localhost\nBIND_PORT=80"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	if !reflect.DeepEqual(res.KeysSet, []string{"BIND_HOST", "BIND_PORT"}) {
+		t.Errorf("Unexpected keys set: %v", res.KeysSet)
+	}
+	// Callers can change the environment between the two phases.
+	t.Setenv("BIND_PORT", "8080")
+	config, err := dotconfig.Bind[bindConfig]()
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := bindConfig{Host: "localhost", Port: 8080}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestDecodeOnlyZeroFields(t *testing.T) {
+	type zeroConfig struct {
+		Port     int    `env:"ZERO_PORT"`
+		LogLevel string `env:"ZERO_LOG_LEVEL"`
+		Region   string `env:"ZERO_REGION"`
+		Name     string
+	}
+	t.Setenv("ZERO_PORT", "9090")
+	t.Setenv("ZERO_LOG_LEVEL", "debug")
+	t.Setenv("ZERO_REGION", "us-west-2")
+	config := zeroConfig{Port: 8080, Name: "app"}
+	if err := dotconfig.Decode(&config, dotconfig.OnlyZeroFields); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := zeroConfig{Port: 8080, LogLevel: "debug", Region: "us-west-2", Name: "app"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	// Without the option, the environment wins.
+	if err := dotconfig.Decode(&config); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected 9090. Got %v.", config.Port)
+	}
+	// Failed decodes leave the struct alone.
+	os.Unsetenv("ZERO_REGION")
+	config = zeroConfig{Name: "app"}
+	if err := dotconfig.Decode(&config); !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Fatalf("Expected %v. Got %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+	if config != (zeroConfig{Name: "app"}) {
+		t.Errorf("Expected config to be unchanged. Got %#v.", config)
+	}
+	if err := dotconfig.Decode(config); err != dotconfig.ErrConfigMustBeStruct {
+		t.Errorf("Expected %v. Got %v.", dotconfig.ErrConfigMustBeStruct, err)
+	}
+}
+
+func TestRefreshFrozen(t *testing.T) {
+	type refreshConfig struct {
+		Addr     string `env:"REFRESH_ADDR,frozen"`
+		LogLevel string `env:"REFRESH_LOG_LEVEL"`
+	}
+	t.Setenv("REFRESH_ADDR", ":8080")
+	t.Setenv("REFRESH_LOG_LEVEL", "info")
+	config, err := dotconfig.Bind[refreshConfig]()
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Setenv("REFRESH_ADDR", ":9090")
+	t.Setenv("REFRESH_LOG_LEVEL", "debug")
+	var warnings []dotconfig.Warning
+	err = dotconfig.Refresh(&config, dotconfig.OnWarning(func(w dotconfig.Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := refreshConfig{Addr: ":8080", LogLevel: "debug"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != dotconfig.WarnFrozenField || warnings[0].Key != "REFRESH_ADDR" {
+		t.Errorf("Expected a frozen field warning. Got %v.", warnings)
+	}
+}
+
+func TestResultUnset(t *testing.T) {
+	type unsetConfig struct {
+		Existing string `env:"UNSET_EXISTING"`
+		New      string `env:"UNSET_NEW"`
+	}
+	t.Setenv("UNSET_EXISTING", "before")
+	r := strings.NewReader(`UNSET_EXISTING=during
+UNSET_NEW=during
+UNSET_EXISTING=during again`)
+	config, res, err := dotconfig.LoadWithResult[unsetConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Existing != "during again" || config.New != "during" {
+		t.Fatalf("Unexpected config: %#v", config)
+	}
+	res.Unset()
+	if v := os.Getenv("UNSET_EXISTING"); v != "before" {
+		t.Errorf("Expected UNSET_EXISTING to be restored. Got %q.", v)
+	}
+	if _, ok := os.LookupEnv("UNSET_NEW"); ok {
+		t.Errorf("Expected UNSET_NEW to be unset.")
+	}
+}
+
+func TestPreferExistingEnv(t *testing.T) {
+	type precedenceConfig struct {
+		Host string `env:"PRECEDENCE_HOST"`
+		Port int    `env:"PRECEDENCE_PORT"`
+	}
+	t.Setenv("PRECEDENCE_HOST", "from-env")
+	r := strings.NewReader("PRECEDENCE_HOST=from-file\nPRECEDENCE_PORT=80\nPRECEDENCE_PORT=8080")
+	config, res, err := dotconfig.LoadWithResult[precedenceConfig](r, dotconfig.PreferExistingEnv)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	expected := precedenceConfig{Host: "from-env", Port: 8080}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestReportConflicts(t *testing.T) {
+	type conflictConfig struct {
+		Host string `env:"CONFLICT_HOST"`
+		Port int    `env:"CONFLICT_PORT"`
+	}
+	t.Setenv("CONFLICT_HOST", "from-env")
+	t.Setenv("CONFLICT_PORT", "8080")
+	r := strings.NewReader("CONFLICT_HOST=from-file\nCONFLICT_PORT=8080")
+	_, res, err := dotconfig.LoadWithResult[conflictConfig](r, dotconfig.ReportConflicts)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	expected := []dotconfig.Warning{{
+		Kind:    dotconfig.WarnConflict,
+		Key:     "CONFLICT_HOST",
+		Line:    1,
+		Message: "CONFLICT_HOST overrides a different value already in the environment",
+	}}
+	if !reflect.DeepEqual(res.Warnings, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, res.Warnings)
+	}
+}
+
+func TestDescInErrors(t *testing.T) {
+	type descConfig struct {
+		SMTPHost string `env:"DESC_SMTP_HOST" desc:"SMTP relay used for outbound mail"`
+		SMTPPort int    `env:"DESC_SMTP_PORT"`
+	}
+	_, err := dotconfig.FromReader[descConfig](strings.NewReader("DESC_SMTP_PORT=25"))
+	expected := "value not present in env: DESC_SMTP_HOST — SMTP relay used for outbound mail"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q. Got %v.", expected, err)
+	}
+	infos := dotconfig.Explain(descConfig{})
+	if len(infos) != 2 || infos[0].Desc != "SMTP relay used for outbound mail" {
+		t.Errorf("Expected desc in report. Got %#v.", infos)
+	}
+}
+
+func TestDeprecatedKeys(t *testing.T) {
+	type deprecatedConfig struct {
+		DatabaseURL string `env:"DEPRECATED_DATABASE_URL" deprecated:"DEPRECATED_DB_URL,DEPRECATED_DSN"`
+	}
+	r := strings.NewReader("DEPRECATED_DSN=postgres://localhost")
+	config, res, err := dotconfig.LoadWithResult[deprecatedConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	if config.DatabaseURL != "postgres://localhost" {
+		t.Errorf("Expected value from deprecated key. Got %q.", config.DatabaseURL)
+	}
+	expected := []dotconfig.Warning{{
+		Kind:    dotconfig.WarnDeprecatedKey,
+		Key:     "DEPRECATED_DSN",
+		Line:    1,
+		Message: "DEPRECATED_DSN is deprecated, use DEPRECATED_DATABASE_URL instead",
+	}}
+	if !reflect.DeepEqual(res.Warnings, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, res.Warnings)
+	}
+}
+
+func TestFieldError(t *testing.T) {
+	type fieldErrorConfig struct {
+		Port     int    `env:"FIELD_ERROR_PORT,autobase"`
+		Hostname string `env:"FIELD_ERROR_HOSTNAME"`
+	}
+	r := strings.NewReader("# Port with a typo\nFIELD_ERROR_PORT=80a0")
+	_, err := dotconfig.FromReader[fieldErrorConfig](r)
+	errs := dotconfig.Errors(err)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors. Got %v.", err)
+	}
+	var fieldErr *dotconfig.FieldError
+	if !errors.As(errs[0], &fieldErr) {
+		t.Fatalf("Expected FieldError. Got %T.", errs[0])
+	}
+	if fieldErr.Field != "Port" || fieldErr.Path != "Port" || fieldErr.Key != "FIELD_ERROR_PORT" || fieldErr.Line != 2 ||
+		fieldErr.Err != dotconfig.ErrInvalidValue || fieldErr.Cause == nil {
+		t.Errorf("Unexpected FieldError: %#v", fieldErr)
+	}
+	if !errors.As(errs[1], &fieldErr) || fieldErr.Key != "FIELD_ERROR_HOSTNAME" || fieldErr.Err != dotconfig.ErrMissingEnvVar {
+		t.Errorf("Unexpected FieldError: %#v", fieldErr)
+	}
+}
+
+func TestJoinedErrorUnwrap(t *testing.T) {
+	type unwrapConfig struct {
+		Missing     string    `env:"UNWRAP_MISSING"`
+		Unsupported complex64 `env:"UNWRAP_UNSUPPORTED"`
+	}
+	_, err := dotconfig.FromReader[unwrapConfig](strings.NewReader("UNWRAP_UNSUPPORTED=1"))
+	if !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected errors.Is to find %v in %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+	if !errors.Is(err, dotconfig.ErrUnsupportedFieldType) {
+		t.Errorf("Expected errors.Is to find %v in %v.", dotconfig.ErrUnsupportedFieldType, err)
+	}
+	var fieldErr *dotconfig.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Key != "UNWRAP_MISSING" {
+		t.Errorf("Expected errors.As to find the first FieldError. Got %#v.", fieldErr)
+	}
+}
+
+func TestErrorsJSON(t *testing.T) {
+	type jsonErrorConfig struct {
+		Host     string `env:"JSON_ERROR_HOST"`
+		Untagged string
+	}
+	_, err := dotconfig.FromReader[jsonErrorConfig](strings.NewReader(""), dotconfig.EnforceStructTags)
+	b, jsonErr := dotconfig.ErrorsJSON(err)
+	if jsonErr != nil {
+		t.Fatalf("Didn't expect error. Got %v.", jsonErr)
+	}
+	expected := `[{"kind":"missing_env_var","field":"Host","path":"Host","key":"JSON_ERROR_HOST","message":"value not present in env: JSON_ERROR_HOST"},` +
+		`{"kind":"missing_struct_tag","field":"Untagged","path":"Untagged","message":"missing struct tag on field: Untagged"}]`
+	if string(b) != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, string(b))
+	}
+	if b, _ := dotconfig.ErrorsJSON(nil); string(b) != "[]" {
+		t.Errorf("Expected empty array. Got %v.", string(b))
+	}
+
+	// Every sentinel error has its own kind, even when it's wrapped.
+	for err, kind := range map[error]string{
+		dotconfig.ErrConfigMustBeStruct:   "config_must_be_struct",
+		dotconfig.ErrMissingStructTag:     "missing_struct_tag",
+		dotconfig.ErrMissingEnvVar:        "missing_env_var",
+		dotconfig.ErrUnsupportedFieldType: "unsupported_field_type",
+		dotconfig.ErrInvalidValue:         "invalid_value",
+		dotconfig.ErrInvalidTag:           "invalid_tag",
+		dotconfig.ErrMalformedLine:        "malformed_line",
+		dotconfig.ErrLoosePermissions:     "loose_permissions",
+		errors.New("something else"):      "unknown",
+	} {
+		b, _ := dotconfig.ErrorsJSON(fmt.Errorf("%w: .env", err))
+		var got []struct{ Kind string }
+		if err := json.Unmarshal(b, &got); err != nil || len(got) != 1 || got[0].Kind != kind {
+			t.Errorf("%v: expected kind %v. Got %v.", err, kind, string(b))
+		}
+	}
+}
+
+func TestAllowPartial(t *testing.T) {
+	type partialConfig struct {
+		Host       string `env:"PARTIAL_HOST"`
+		SMTPServer string `env:"PARTIAL_SMTP_SERVER"`
+	}
+	r := strings.NewReader("PARTIAL_HOST=localhost")
+	config, err := dotconfig.FromReader[partialConfig](r)
+	if err == nil || config != (partialConfig{}) {
+		t.Errorf("Expected error and zero config by default. Got %#v, %v.", config, err)
+	}
+	config, err = dotconfig.FromReader[partialConfig](strings.NewReader(""), dotconfig.AllowPartial)
+	if err == nil || config.Host != "localhost" {
+		t.Errorf("Expected error and partial config. Got %#v, %v.", config, err)
+	}
+}
+
+func TestOnWarning(t *testing.T) {
+	type warningConfig struct {
+		Host string `env:"WARNING_HOST"`
+	}
+	r := strings.NewReader("WARNING_HOST=localhost   \nWARNING_TYPO=oops")
+	var warnings []dotconfig.Warning
+	_, err := dotconfig.FromReader[warningConfig](r, dotconfig.OnWarning(func(w dotconfig.Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []string{
+		"line 1: trailing whitespace trimmed from WARNING_HOST",
+		"line 2: WARNING_TYPO doesn't match any field",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %v. Got %v.", expected, warnings)
+	}
+	for i, w := range warnings {
+		if w.String() != expected[i] {
+			t.Errorf("Expected %q. Got %q.", expected[i], w)
+		}
+	}
+}
+
+func TestOnWarningWithErrors(t *testing.T) {
+	type warningConfig struct {
+		Host string `env:"WARNING_ERR_HOST"`
+		Port int    `env:"WARNING_ERR_PORT,required"`
+	}
+	var warnings []string
+	onWarning := dotconfig.OnWarning(func(w dotconfig.Warning) {
+		warnings = append(warnings, w.String())
+	})
+	environ := dotconfig.WithEnviron(map[string]string{})
+
+	// A parse error still reports the warnings from the lines before it.
+	r := strings.NewReader("WARNING_ERR_HOST=localhost   \nWARNING_ERR_PORT=\"8080")
+	_, res, err := dotconfig.LoadWithResult[warningConfig](r, onWarning, environ)
+	if err == nil {
+		t.Fatal("Expected parse error. Got nil.")
+	}
+	expected := []string{"line 1: trailing whitespace trimmed from WARNING_ERR_HOST"}
+	if !reflect.DeepEqual(warnings, expected) || len(res.Warnings) != 1 {
+		t.Errorf("Expected %v. Got %v.", expected, warnings)
+	}
+
+	// So does a decode error.
+	warnings = nil
+	r = strings.NewReader("WARNING_ERR_HOST=localhost   ")
+	_, _, err = dotconfig.LoadWithResult[warningConfig](r, onWarning, environ)
+	if !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Fatalf("Expected %v. Got %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, warnings)
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	type maxErrorsConfig struct {
+		A string `env:"MAX_ERRORS_A"`
+		B string `env:"MAX_ERRORS_B"`
+		C string `env:"MAX_ERRORS_C"`
+	}
+	_, err := dotconfig.FromReader[maxErrorsConfig](strings.NewReader(""), dotconfig.MaxErrors(2))
+	expected := `multiple errors:
+- value not present in env: MAX_ERRORS_A
+- value not present in env: MAX_ERRORS_B
+- and 1 more`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, err)
+	}
+	if errs := dotconfig.Errors(err); len(errs) != 2 {
+		t.Errorf("Expected 2 errors. Got %v.", len(errs))
+	}
+}
+
+func TestInvalidTags(t *testing.T) {
+	type invalidTagConfig struct {
+		Typo          string `env:"INVALID_TAG_TYPO,requierd"`
+		Contradiction string `env:"INVALID_TAG_CONTRADICTION,required,optional"`
+		Default       string `env:"INVALID_TAG_DEFAULT,required" default:"x"`
+		Trim          string `env:"INVALID_TAG_TRIM,trim,notrim"`
+		Case          string `env:"INVALID_TAG_CASE,lower,upper"`
+		Expand        string `env:"INVALID_TAG_EXPAND,expand,noexpand"`
+		RequiredIf    string `env:"INVALID_TAG_REQUIRED_IF" requiredif:"INVALID_TAG_FINE"`
+		Fine          string `env:"INVALID_TAG_FINE,required"`
+	}
+	_, err := dotconfig.FromReader[invalidTagConfig](strings.NewReader("INVALID_TAG_FINE=ok"))
+	expected := []string{
+		`invalid struct tag: INVALID_TAG_TYPO: unknown option "requierd"`,
+		"invalid struct tag: INVALID_TAG_CONTRADICTION: can't be both required and optional",
+		"invalid struct tag: INVALID_TAG_DEFAULT: required fields can't have a default",
+		"invalid struct tag: INVALID_TAG_TRIM: can't be both trim and notrim",
+		"invalid struct tag: INVALID_TAG_CASE: can't be both lower and upper",
+		"invalid struct tag: INVALID_TAG_EXPAND: can't be both expand and noexpand",
+		`invalid struct tag: INVALID_TAG_REQUIRED_IF: requiredif must look like "KEY=value"`,
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expected), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected %q. Got %q.", expected[i], err)
+		}
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	type validConfig struct {
+		Host    string         `env:"HOST"`
+		Port    int            `env:"PORT" default:"8080"`
+		TZ      *time.Location `env:"TZ,optional"`
+		ignored string
+	}
+	if err := dotconfig.ValidateStruct[validConfig](); err != nil {
+		t.Errorf("Didn't expect error. Got %v.", err)
+	}
+
+	type invalidConfig struct {
+		Port      int       `env:"PORT" default:"eighty"`
+		Duplicate string    `env:"PORT"`
+		Typo      string    `env:"TYPO,secert"`
+		Complex   complex64 `env:"COMPLEX"`
+		Token     string    `env:"TOKEN" ttl:"soon"`
+		Rest      []string  `env:",rest"`
+	}
+	err := dotconfig.ValidateStruct[invalidConfig]()
+	expected := []string{
+		`invalid value: PORT: strconv.ParseInt: parsing "eighty": invalid syntax`,
+		"invalid struct tag: PORT: key is also used by Port",
+		`invalid struct tag: TYPO: unknown option "secert"`,
+		"unsupported field type: complex64",
+		`invalid struct tag: TOKEN: time: invalid duration "soon"`,
+		"unsupported field type: rest fields must be map[string]string",
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expected), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected %q. Got %q.", expected[i], err)
+		}
+	}
+}
+
+func TestEmptyAsMissing(t *testing.T) {
+	type emptyConfig struct {
+		Port     int    `env:"EMPTY_PORT" default:"8080"`
+		LogLevel string `env:"EMPTY_LOG_LEVEL,optional"`
+		Host     string `env:"EMPTY_HOST"`
+	}
+	r := strings.NewReader("EMPTY_PORT=\nEMPTY_LOG_LEVEL=''\nEMPTY_HOST=")
+	config, err := dotconfig.FromReader[emptyConfig](r, dotconfig.EmptyAsMissing, dotconfig.AllowPartial)
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected missing EMPTY_HOST error. Got %v.", err)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected default to be applied. Got %v.", config.Port)
+	}
+}
+
+func TestPresentAndNonzero(t *testing.T) {
+	type requiredConfig struct {
+		Present     string `env:"REQUIRED_PRESENT,present"`
+		Nonzero     int    `env:"REQUIRED_NONZERO,nonzero"`
+		OptionalNZ  int    `env:"REQUIRED_OPTIONAL_NONZERO,optional,nonzero"`
+		DefaultedNZ int    `env:"REQUIRED_DEFAULTED_NONZERO,nonzero" default:"0"`
+	}
+	r := strings.NewReader("REQUIRED_PRESENT=\nREQUIRED_NONZERO=0")
+	_, err := dotconfig.FromReader[requiredConfig](r)
+	expected := []string{
+		"invalid value: REQUIRED_NONZERO: must be non-zero",
+		"invalid value: REQUIRED_DEFAULTED_NONZERO: must be non-zero",
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expected), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected %q. Got %q.", expected[i], err)
+		}
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	type requiredIfConfig struct {
+		LogEmail  bool   `env:"REQUIRED_IF_LOG_EMAIL"`
+		SMTPHost  string `env:"REQUIRED_IF_SMTP_HOST" requiredif:"REQUIRED_IF_LOG_EMAIL=true"`
+		LogFile   string `env:"REQUIRED_IF_LOG_FILE" requiredif:"REQUIRED_IF_LOG_EMAIL=false"`
+		SlackHook string `env:"REQUIRED_IF_SLACK_HOOK" requiredif:"REQUIRED_IF_SLACK=on"`
+	}
+	_, err := dotconfig.FromReader[requiredIfConfig](strings.NewReader("REQUIRED_IF_LOG_EMAIL=TRUE"))
+	expected := []string{
+		"value not present in env: REQUIRED_IF_SMTP_HOST: required when REQUIRED_IF_LOG_EMAIL=true",
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expected), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected %q. Got %q.", expected[i], err)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	type groupConfig struct {
+		SMTPHost     string `env:"GROUP_SMTP_HOST" group:"smtp"`
+		SMTPPort     int    `env:"GROUP_SMTP_PORT" group:"smtp" default:"587"`
+		SMTPPassword string `env:"GROUP_SMTP_PASSWORD" group:"smtp"`
+		SMTPFrom     string `env:"GROUP_SMTP_FROM,optional" group:"smtp"`
+		TLSCert      string `env:"GROUP_TLS_CERT" group:"tls"`
+		TLSKey       string `env:"GROUP_TLS_KEY" group:"tls"`
+	}
+	// Nothing in either group is set, so none of it is required. Loads
+	// use their own environment so the process one is left alone.
+	config, res, err := dotconfig.LoadWithResult[groupConfig](strings.NewReader(""), dotconfig.WithEnviron(map[string]string{}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.SMTPPort != 587 || len(res.Skipped) != 5 {
+		t.Errorf("Expected default port and 5 skipped fields. Got %v and %v.", config.SMTPPort, res.Skipped)
+	}
+
+	_, err = dotconfig.FromReader[groupConfig](strings.NewReader("GROUP_SMTP_HOST=mail.example.com"), dotconfig.WithEnviron(map[string]string{}))
+	expected := []string{
+		"value not present in env: GROUP_SMTP_PASSWORD: required because GROUP_SMTP_HOST is set (group smtp)",
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expected), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected %q. Got %q.", expected[i], err)
+		}
+	}
+
+	type badGroupConfig struct {
+		Host string `env:"GROUP_BAD_HOST,required" group:"smtp"`
+	}
+	if err := dotconfig.ValidateStruct[badGroupConfig](); !errors.Is(err, dotconfig.ErrInvalidTag) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidTag, err)
+	}
+}
+
+func TestRestField(t *testing.T) {
+	type restConfig struct {
+		Port int               `env:"REST_PORT"`
+		Rest map[string]string `env:",rest"`
+	}
+	r := strings.NewReader("REST_PORT=8080\nREST_UPSTREAM_A=a\nREST_UPSTREAM_B=b")
+	config, res, err := dotconfig.LoadWithResult[restConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	expected := restConfig{Port: 8080, Rest: map[string]string{"REST_UPSTREAM_A": "a", "REST_UPSTREAM_B": "b"}}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	// Keys that go in the rest field aren't unknown.
+	if len(res.Warnings) != 0 {
+		t.Errorf("Expected no warnings. Got %v.", res.Warnings)
+	}
+
+	type badRestConfig struct {
+		Rest  map[string]int    `env:",rest"`
+		Rest2 map[string]string `env:",rest"`
+		Rest3 map[string]string `env:",rest"`
+	}
+	_, err = dotconfig.FromReader[badRestConfig](strings.NewReader(""))
+	expected2 := []string{
+		"unsupported field type: rest fields must be map[string]string",
+		"invalid struct tag: Rest3: only one field can be tagged rest",
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expected2) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expected2), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expected2[i] {
+			t.Errorf("Expected %q. Got %q.", expected2[i], err)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	type templateConfig struct {
+		Addr  string `env:"TEMPLATE_ADDR"`
+		Cache string `env:"TEMPLATE_CACHE_DIR"`
+	}
+	t.Setenv("TEMPLATE_HOME", "/home/app")
+	r := strings.NewReader(`TEMPLATE_ADDR={{ .PodIP }}:8080
+TEMPLATE_CACHE_DIR={{ env "TEMPLATE_HOME" }}/.cache`)
+	config, err := dotconfig.FromReader[templateConfig](r, dotconfig.Template(map[string]any{"PodIP": "10.0.0.5"}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := templateConfig{Addr: "10.0.0.5:8080", Cache: "/home/app/.cache"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	// Missing data is an error instead of "<no value>".
+	_, err = dotconfig.FromReader[templateConfig](strings.NewReader("TEMPLATE_ADDR={{ .HostIP }}"), dotconfig.Template(nil))
+	if err == nil {
+		t.Errorf("Expected error for missing template data.")
+	}
+}
+
+func TestAppendValues(t *testing.T) {
+	type appendConfig struct {
+		Features string `env:"APPEND_FEATURES"`
+		Path     string `env:"APPEND_PATH"`
+	}
+	t.Setenv("APPEND_PATH", "/usr/bin")
+	r := strings.NewReader(`APPEND_FEATURES=search
+APPEND_FEATURES+=billing
+APPEND_PATH+=/opt/app/bin`)
+	config, err := dotconfig.FromReader[appendConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := appendConfig{Features: "search,billing", Path: "/usr/bin,/opt/app/bin"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	os.Setenv("APPEND_PATH", "/usr/bin")
+	r = strings.NewReader("APPEND_PATH+=/opt/app/bin")
+	config, err = dotconfig.FromReader[appendConfig](r, dotconfig.AppendSeparator(":"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Path != "/usr/bin:/opt/app/bin" {
+		t.Errorf("Expected /usr/bin:/opt/app/bin. Got %v.", config.Path)
+	}
+}
+
+func TestEntryValues(t *testing.T) {
+	// APPEND_PATH is set, but EntryValues only adds to earlier entries.
+	t.Setenv("APPEND_PATH", "/usr/bin")
+	entries, err := dotconfig.Parse(strings.NewReader("APPEND_PATH+=/opt/app/bin\n[dev]\nAPPEND_PATH+=/opt/dev/bin\n[prod]\nAPPEND_PATH=/opt/prod/bin\n"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	values := dotconfig.EntryValues(entries, dotconfig.Profile("dev"), dotconfig.AppendSeparator(":"))
+	expected := map[string]string{"APPEND_PATH": "/opt/app/bin:/opt/dev/bin"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, values)
+	}
+}
+
+func TestTrimTags(t *testing.T) {
+	type trimConfig struct {
+		Token   string `env:"TRIM_TOKEN,trim"`
+		Port    int    `env:"TRIM_PORT,trim"`
+		Prefix  string `env:"TRIM_PREFIX,notrim"`
+		Spacer  string `env:"TRIM_SPACER,notrim"`
+		Default string `env:"TRIM_DEFAULT"`
+	}
+	t.Setenv("TRIM_TOKEN", "abc123\n")
+	t.Setenv("TRIM_PORT", " 8080 ")
+	t.Setenv("TRIM_PREFIX", "> ")
+	t.Setenv("TRIM_SPACER", "   ")
+	t.Setenv("TRIM_DEFAULT", "   ")
+	config, err := dotconfig.FromReader[trimConfig](strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := trimConfig{Token: "abc123", Port: 8080, Prefix: "> ", Spacer: "   "}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestCaseTags(t *testing.T) {
+	type caseConfig struct {
+		LogLevel string `env:"CASE_LOG_LEVEL,lower"`
+		Region   string `env:"CASE_REGION,upper" default:"us"`
+		Name     string `env:"CASE_NAME"`
+	}
+	r := strings.NewReader("CASE_LOG_LEVEL=DEBUG\nCASE_NAME=MyApp")
+	config, err := dotconfig.FromReader[caseConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := caseConfig{LogLevel: "debug", Region: "US", Name: "MyApp"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestExpandVariables(t *testing.T) {
+	type expandConfig struct {
+		URL      string `env:"EXPAND_URL"`
+		Template string `env:"EXPAND_TEMPLATE,noexpand"`
+		Cache    string `env:"EXPAND_CACHE" default:"${EXPAND_HOME}/.cache"`
+		Password string `env:"EXPAND_PASSWORD"`
+	}
+	t.Setenv("EXPAND_HOME", "/home/app")
+	r := strings.NewReader(`EXPAND_HOST=db.internal
+EXPAND_URL=postgres://${EXPAND_HOST}:5432/${EXPAND_MISSING}
+EXPAND_TEMPLATE=Hello ${name}
+EXPAND_PASSWORD=pa$word`)
+	config, err := dotconfig.FromReader[expandConfig](r, dotconfig.ExpandVariables)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := expandConfig{
+		URL:      "postgres://db.internal:5432/",
+		Template: "Hello ${name}",
+		Cache:    "/home/app/.cache",
+		Password: "pa$word",
+	}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	// Without the option, only fields tagged expand are expanded.
+	type optInConfig struct {
+		URL   string `env:"EXPAND_URL,expand"`
+		Cache string `env:"EXPAND_CACHE" default:"${EXPAND_HOME}/.cache"`
+	}
+	optIn, err := dotconfig.FromReader[optInConfig](strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if optIn.URL != "postgres://db.internal:5432/" || optIn.Cache != "${EXPAND_HOME}/.cache" {
+		t.Errorf("Unexpected config: %#v", optIn)
+	}
+}
+
+func TestExpandWindowsVariables(t *testing.T) {
+	type percentConfig struct {
+		Dir      string `env:"PERCENT_DIR"`
+		Discount string `env:"PERCENT_DISCOUNT"`
+		Unset    string `env:"PERCENT_UNSET"`
+		Literal  string `env:"PERCENT_LITERAL"`
+		Template string `env:"PERCENT_TEMPLATE,noexpand"`
+	}
+	t.Setenv("PERCENT_PROFILE", `C:\Users\app`)
+	r := strings.NewReader(`PERCENT_DIR=%PERCENT_PROFILE%\AppData
+PERCENT_DISCOUNT=50% off
+PERCENT_UNSET=%PERCENT_MISSING%%PERCENT_PROFILE%
+PERCENT_LITERAL=100%%
+PERCENT_TEMPLATE=%PERCENT_PROFILE%`)
+	config, err := dotconfig.FromReader[percentConfig](r, dotconfig.ExpandWindowsVariables)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := percentConfig{
+		Dir:      `C:\Users\app\AppData`,
+		Discount: "50% off",
+		Unset:    `%PERCENT_MISSING%C:\Users\app`,
+		Literal:  "100%",
+		Template: "%PERCENT_PROFILE%",
+	}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestWithEnviron(t *testing.T) {
+	type environConfig struct {
+		Port    int    `env:"ENVIRON_PORT"`
+		Host    string `env:"ENVIRON_HOST"`
+		BaseURL string `env:"ENVIRON_BASE_URL,expand"`
+	}
+	t.Setenv("ENVIRON_PORT", "1")
+	env := dotconfig.WithEnviron(map[string]string{"ENVIRON_PORT": "8080"})
+	r := strings.NewReader("ENVIRON_HOST=example.com\nENVIRON_BASE_URL=https://${ENVIRON_HOST}:${ENVIRON_PORT}")
+	config, err := dotconfig.FromReader[environConfig](r, env)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := environConfig{Port: 8080, Host: "example.com", BaseURL: "https://example.com:8080"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	// Nothing leaks into or out of the process environment.
+	if _, ok := os.LookupEnv("ENVIRON_HOST"); ok || os.Getenv("ENVIRON_PORT") != "1" {
+		t.Errorf("Expected process environment to be untouched.")
+	}
+
+	// Each load starts from the snapshot, so values loaded with one
+	// option aren't seen by later loads with it, or by the snapshot.
+	snapshot := map[string]string{"ENVIRON_PORT": "9090", "ENVIRON_BASE_URL": "x"}
+	env = dotconfig.WithEnviron(snapshot)
+	if _, err := dotconfig.Load(strings.NewReader("ENVIRON_HOST=example.org"), env); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	config, err = dotconfig.FromReader[environConfig](strings.NewReader("ENVIRON_HOST=example.net"), env)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected = environConfig{Port: 9090, Host: "example.net", BaseURL: "x"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	if _, err := dotconfig.Bind[environConfig](env); !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+	if _, ok := snapshot["ENVIRON_HOST"]; ok {
+		t.Errorf("Expected snapshot to be untouched. Got %v.", snapshot)
+	}
+
+	_, err = dotconfig.Bind[environConfig](dotconfig.WithEnviron([]string{}))
+	if errs := dotconfig.Errors(err); len(errs) != 3 {
+		t.Errorf("Expected 3 errors. Got %v.", err)
+	}
+}
+
+func TestNoExport(t *testing.T) {
+	type noExportConfig struct {
+		Password string `env:"NO_EXPORT_PASSWORD"`
+		Proxy    string `env:"NO_EXPORT_PROXY,export"`
+		Region   string `env:"NO_EXPORT_REGION"`
+		Greeting string `env:"NO_EXPORT_GREETING,expand"`
+	}
+	t.Setenv("NO_EXPORT_REGION", "us-west-2")
+	r := strings.NewReader("NO_EXPORT_PASSWORD=hunter2\nNO_EXPORT_PROXY=http://proxy:3128\nNO_EXPORT_GREETING=hi from ${NO_EXPORT_REGION} ${NO_EXPORT_PASSWORD}")
+	config, res, err := dotconfig.LoadWithResult[noExportConfig](r, dotconfig.NoExport)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := noExportConfig{Password: "hunter2", Proxy: "http://proxy:3128", Region: "us-west-2", Greeting: "hi from us-west-2 hunter2"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+	if _, ok := os.LookupEnv("NO_EXPORT_PASSWORD"); ok {
+		t.Errorf("Expected NO_EXPORT_PASSWORD to stay out of the environment.")
+	}
+	if os.Getenv("NO_EXPORT_PROXY") != "http://proxy:3128" {
+		t.Errorf("Expected NO_EXPORT_PROXY to be exported. Got %q.", os.Getenv("NO_EXPORT_PROXY"))
+	}
+	res.Unset()
+	if _, ok := os.LookupEnv("NO_EXPORT_PROXY"); ok {
+		t.Errorf("Expected Unset to remove NO_EXPORT_PROXY.")
+	}
+}
+
+func TestFeatureFlags(t *testing.T) {
+	type flagsConfig struct {
+		Port     int                    `env:"FEATURE_PORT"`
+		Features dotconfig.FeatureFlags `env:"FEATURE_FF_"`
+	}
+	t.Setenv("FEATURE_FF_DARK_MODE", "yes")
+	r := strings.NewReader("FEATURE_PORT=80\nFEATURE_FF_NEW_BILLING=on\nFEATURE_FF_BETA_API=0\nFEATURE_FF_LEGACY=")
+	config, res, err := dotconfig.LoadWithResult[flagsConfig](r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	expected := dotconfig.FeatureFlags{"new_billing": true, "beta_api": false, "legacy": false, "dark_mode": true}
+	if !reflect.DeepEqual(config.Features, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, config.Features)
+	}
+	if !config.Features.Enabled("NEW_BILLING") || config.Features.Enabled("beta_api") || config.Features.Enabled("missing") {
+		t.Errorf("Expected only new_billing and dark_mode to be enabled. Got %v.", config.Features)
+	}
+	if len(res.Warnings) != 0 {
+		t.Errorf("Expected no unknown key warnings. Got %v.", res.Warnings)
+	}
+
+	_, err = dotconfig.FromReader[flagsConfig](strings.NewReader("FEATURE_FF_NEW_BILLING=maybe"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) || !strings.Contains(err.Error(), "FEATURE_FF_NEW_BILLING") {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+	}
+	if err := dotconfig.ValidateStruct[flagsConfig](); err != nil {
+		t.Errorf("Didn't expect error. Got %v.", err)
+	}
+}
+
+func BenchmarkBind(b *testing.B) {
+	type benchConfig struct {
+		Host     string        `env:"BENCH_HOST"`
+		Port     int           `env:"BENCH_PORT"`
+		Debug    bool          `env:"BENCH_DEBUG,optional"`
+		Timeout  time.Duration `env:"BENCH_TIMEOUT" default:"5s"`
+		Regions  []string      `env:"BENCH_REGIONS"`
+		Password string        `env:"BENCH_PASSWORD,secret" deprecated:"BENCH_PASS"`
+	}
+	b.Setenv("BENCH_HOST", "localhost")
+	b.Setenv("BENCH_PORT", "8080")
+	b.Setenv("BENCH_REGIONS", "us,eu")
+	b.Setenv("BENCH_PASSWORD", "hunter2")
+	expected := benchConfig{
+		Host:     "localhost",
+		Port:     8080,
+		Timeout:  5 * time.Second,
+		Regions:  []string{"us", "eu"},
+		Password: "hunter2",
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		config, err := dotconfig.Bind[benchConfig]()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if i == 0 && !reflect.DeepEqual(config, expected) {
+			b.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, config)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Package dotconfigexpvar publishes config values with [expvar].
+//
+// This lives outside of package dotconfig because importing expvar
+// registers a /debug/vars handler on [http.DefaultServeMux], and we
+// don't want that to happen to everybody who imports dotconfig.
+package dotconfigexpvar
+
+import (
+	"expvar"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+// Publish publishes the non-secret values of config under an
+// [expvar.Map] called name, keyed by env key. Fields tagged
+// `env:"KEY,secret"` are left out entirely. The map is reused if it
+// already exists, so it's safe to call Publish again after a reload:
+//
+//	store.OnChange(func(old, new AppConfig, changes dotconfig.Changes) {
+//		dotconfigexpvar.Publish("config", new)
+//	})
+//
+// Pass the options you decoded with so the values match what
+// [dotconfig.Explain] would report.
+func Publish[T any](name string, config T, opts ...dotconfig.DecodeOption) {
+	m, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		m = expvar.NewMap(name)
+	}
+	for _, info := range dotconfig.Explain(config, opts...) {
+		if info.Secret {
+			continue
+		}
+		v := new(expvar.String)
+		v.Set(info.Value)
+		m.Set(info.Key, v)
+	}
+}
@@ -0,0 +1,32 @@
+package dotconfigexpvar_test
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig/dotconfigexpvar"
+)
+
+type testConfig struct {
+	Region string `env:"REGION"`
+	APIKey string `env:"API_KEY,secret"`
+}
+
+func TestPublish(t *testing.T) {
+	dotconfigexpvar.Publish("testconfig", testConfig{Region: "us-west-2", APIKey: "abc123"})
+	m, ok := expvar.Get("testconfig").(*expvar.Map)
+	if !ok {
+		t.Fatalf("Expected expvar map to be published.")
+	}
+	if v := m.Get("REGION"); v == nil || v.String() != `"us-west-2"` {
+		t.Errorf("Expected region to be published. Got %v.", v)
+	}
+	if v := m.Get("API_KEY"); v != nil {
+		t.Errorf("Expected secret to be left out. Got %v.", v)
+	}
+	// Publishing again under the same name shouldn't panic.
+	dotconfigexpvar.Publish("testconfig", testConfig{Region: "us-east-1"})
+	if v := m.Get("REGION"); v.String() != `"us-east-1"` {
+		t.Errorf("Expected region to be updated. Got %v.", v)
+	}
+}
@@ -0,0 +1,166 @@
+// Package dotconfigkoanf connects dotconfig to koanf
+// (github.com/knadh/koanf). It works both ways: env files can be loaded
+// into koanf with [Parser] or [File], and koanf can be used as a
+// [dotconfig.Source] so its values are decoded with dotconfig's struct
+// tags.
+//
+// koanf's Parser and Provider are small interfaces, so this package
+// implements them without importing koanf.
+package dotconfigkoanf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+// EnvParser is a koanf Parser for env files. Get one with [Parser].
+type EnvParser struct {
+	opts []dotconfig.DecodeOption
+}
+
+// Parser returns a koanf Parser that reads env files with
+// [dotconfig.Parse]:
+//
+//	k := koanf.New(".")
+//	err := k.Load(file.Provider(".env"), dotconfigkoanf.Parser())
+//
+// opts are passed to [dotconfig.Parse] and [dotconfig.EntryValues], so
+// [dotconfig.Profile] picks the section to read and
+// [dotconfig.AppendSeparator] joins KEY+=value lines.
+func Parser(opts ...dotconfig.DecodeOption) *EnvParser {
+	return &EnvParser{opts: opts}
+}
+
+// Unmarshal parses an env file into a flat map of keys to string values.
+// Values are resolved like they are when loading with dotconfig: later
+// lines override earlier ones, KEY+=value lines add to earlier values,
+// and sections other than the [dotconfig.Profile] are left out.
+func (p *EnvParser) Unmarshal(b []byte) (map[string]interface{}, error) {
+	entries, err := dotconfig.Parse(bytes.NewReader(b), p.opts...)
+	if err != nil {
+		return nil, err
+	}
+	resolved := dotconfig.EntryValues(entries, p.opts...)
+	values := make(map[string]interface{}, len(resolved))
+	for key, value := range resolved {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Marshal writes values as an env file with keys in sorted order. Nested
+// maps are flattened by joining keys with underscores, and slices are
+// joined with commas. Values with " #" or a literal \n can't be read
+// back the same, so they're an error.
+func (p *EnvParser) Marshal(values map[string]interface{}) ([]byte, error) {
+	flat := map[string]string{}
+	flatten("", values, flat)
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, key := range keys {
+		value, err := quote(flat[key])
+		if err != nil {
+			return nil, fmt.Errorf("dotconfigkoanf: %v: %w", key, err)
+		}
+		fmt.Fprintf(&buf, "%v=%v\n", key, value)
+	}
+	return buf.Bytes(), nil
+}
+
+// flatten adds the values in m to flat, with keys prefixed by prefix.
+func flatten(prefix string, m map[string]interface{}, flat map[string]string) {
+	for key, value := range m {
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(key, v, flat)
+		case []interface{}:
+			parts := make([]string, len(v))
+			for i, part := range v {
+				parts[i] = fmt.Sprint(part)
+			}
+			flat[key] = strings.Join(parts, ",")
+		default:
+			flat[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// quote double quotes value if it wouldn't survive being parsed as-is,
+// and escapes newlines. The parser has no other escapes and " #" starts
+// a comment even in quotes, so values with either are an error.
+func quote(value string) (string, error) {
+	if strings.Contains(value, " #") || strings.Contains(value, `\n`) {
+		return "", errors.New(`values with " #" or a literal \n can't be written to an env file`)
+	}
+	if strings.TrimSpace(value) == value && !strings.ContainsAny(value, "\n\r#'\"") {
+		return value, nil
+	}
+	return `"` + strings.ReplaceAll(value, "\n", `\n`) + `"`, nil
+}
+
+// FileProvider is a koanf Provider for an env file. Get one with [File].
+type FileProvider struct {
+	path string
+	opts []dotconfig.DecodeOption
+}
+
+// File returns a koanf Provider that reads the env file at path. Use it
+// without a parser since it parses the file itself:
+//
+//	err := k.Load(dotconfigkoanf.File(".env"), nil)
+//
+// opts are used like they are by [Parser].
+func File(path string, opts ...dotconfig.DecodeOption) *FileProvider {
+	return &FileProvider{path: path, opts: opts}
+}
+
+// ReadBytes returns the contents of the file.
+func (f *FileProvider) ReadBytes() ([]byte, error) {
+	return os.ReadFile(f.path)
+}
+
+// Read parses the file into a flat map of keys to string values.
+func (f *FileProvider) Read() (map[string]interface{}, error) {
+	b, err := f.ReadBytes()
+	if err != nil {
+		return nil, err
+	}
+	return Parser(f.opts...).Unmarshal(b)
+}
+
+// Flattener is the part of *koanf.Koanf used by [Source].
+type Flattener interface {
+	All() map[string]interface{}
+}
+
+// Source returns a [dotconfig.Source] that reads from k. koanf keys are
+// turned into env keys by uppercasing them and replacing the delimiter
+// (usually ".") with underscores, so "database.host" is decoded into a
+// field tagged `env:"DATABASE_HOST"`:
+//
+//	conf, err := dotconfig.FromSource[AppConfig](ctx, dotconfigkoanf.Source(k, "."))
+func Source(k Flattener, delim string) dotconfig.Source {
+	return dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		flat := map[string]string{}
+		for key, value := range k.All() {
+			flatten("", map[string]interface{}{
+				strings.ToUpper(strings.ReplaceAll(key, delim, "_")): value,
+			}, flat)
+		}
+		return flat, nil
+	})
+}
@@ -0,0 +1,122 @@
+package dotconfigkoanf_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+	"github.com/DeanPDX/dotconfig/dotconfigkoanf"
+)
+
+func TestParser(t *testing.T) {
+	values, err := dotconfigkoanf.Parser().Unmarshal([]byte("REGION=us-west-2\nGREETING='hello world'\nREGION=us-east-1\n"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := map[string]interface{}{"REGION": "us-east-1", "GREETING": "hello world"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, values)
+	}
+	b, err := dotconfigkoanf.Parser().Marshal(map[string]interface{}{
+		"REGION":   "us-east-1",
+		"GREETING": "hello\nworld",
+		"DATABASE": map[string]interface{}{"PORT": 5432},
+		"HOSTS":    []interface{}{"a", "b"},
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expectedFile := "DATABASE_PORT=5432\nGREETING=\"hello\\nworld\"\nHOSTS=a,b\nREGION=us-east-1\n"
+	if string(b) != expectedFile {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expectedFile, string(b))
+	}
+}
+
+func TestParserProfiles(t *testing.T) {
+	env := []byte("LOG_LEVEL=info\nFEATURES=a\nFEATURES+=b\n[dev]\nLOG_LEVEL=debug\nFEATURES+=c\n")
+	values, err := dotconfigkoanf.Parser().Unmarshal(env)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := map[string]interface{}{"LOG_LEVEL": "info", "FEATURES": "a,b"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, values)
+	}
+	values, err = dotconfigkoanf.Parser(dotconfig.Profile("dev"), dotconfig.AppendSeparator(":")).Unmarshal(env)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected = map[string]interface{}{"LOG_LEVEL": "debug", "FEATURES": "a:b:c"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, values)
+	}
+}
+
+func TestParserRoundTrip(t *testing.T) {
+	values := map[string]interface{}{}
+	for i, value := range []string{
+		"", " lead", "trail ", "a b", "tab\there", "#hash", "a#b", "it's", `say "hi"`,
+		`"quoted"`, "'single'", `"`, "'", "multi\nline", "\n", `back\slash`, `ends\`,
+		"back\\\nslash", "cr\r", "=eq=", "[section]", "émoji 🎉",
+	} {
+		values[fmt.Sprintf("KEY_%02d", i)] = value
+	}
+	b, err := dotconfigkoanf.Parser().Marshal(values)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	got, err := dotconfigkoanf.Parser().Unmarshal(b)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", values, got)
+	}
+
+	for _, value := range []string{"a #b", `literal\n`} {
+		if _, err := dotconfigkoanf.Parser().Marshal(map[string]interface{}{"KEY": value}); err == nil {
+			t.Errorf("Expected error writing %q.", value)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("REGION=us-west-2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	values, err := dotconfigkoanf.File(path).Read()
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if values["REGION"] != "us-west-2" {
+		t.Errorf("Expected us-west-2. Got %v.", values["REGION"])
+	}
+}
+
+// fakeKoanf has the same All method as *koanf.Koanf.
+type fakeKoanf map[string]interface{}
+
+func (k fakeKoanf) All() map[string]interface{} {
+	return k
+}
+
+func TestSource(t *testing.T) {
+	type koanfConfig struct {
+		Host string `env:"KOANF_DATABASE_HOST"`
+		Port int    `env:"KOANF_DATABASE_PORT"`
+	}
+	k := fakeKoanf{"koanf.database.host": "db.internal", "koanf.database.port": 5432}
+	config, err := dotconfig.FromSource[koanfConfig](context.Background(), dotconfigkoanf.Source(k, "."))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := koanfConfig{Host: "db.internal", Port: 5432}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
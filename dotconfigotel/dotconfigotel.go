@@ -0,0 +1,64 @@
+// Package dotconfigotel adds OpenTelemetry tracing to config sources.
+//
+// This lives outside of package dotconfig so that only people who want
+// tracing depend on OpenTelemetry.
+package dotconfigotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+const tracerName = "github.com/DeanPDX/dotconfig/dotconfigotel"
+
+// Attribute keys set on each span.
+const (
+	SourceTypeKey  = attribute.Key("dotconfig.source.type")
+	KeysFetchedKey = attribute.Key("dotconfig.keys.fetched")
+)
+
+// TraceSource wraps src so every Fetch is recorded as a span called
+// "dotconfig.Fetch" using the global tracer provider. sourceType (for
+// example "vault" or "ssm") and the number of keys fetched are added as
+// attributes, and failed fetches are marked as errors. That way a slow
+// startup caused by secret manager latency shows up in your traces:
+//
+//	src := dotconfigotel.TraceSource(vault, "vault")
+//	conf, err := dotconfig.FromSource[AppConfig](ctx, src)
+//
+// Keys and values are never recorded since they may be secrets. A name
+// given to src with [dotconfig.NamedSource] is kept, so source tags
+// still work.
+func TraceSource(src dotconfig.Source, sourceType string) dotconfig.Source {
+	return tracedSource{src: src, sourceType: sourceType}
+}
+
+type tracedSource struct {
+	src        dotconfig.Source
+	sourceType string
+}
+
+func (s tracedSource) Fetch(ctx context.Context) (map[string]string, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "dotconfig.Fetch",
+		trace.WithAttributes(SourceTypeKey.String(s.sourceType)))
+	defer span.End()
+	values, err := s.src.Fetch(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return values, err
+	}
+	span.SetAttributes(KeysFetchedKey.Int(len(values)))
+	return values, nil
+}
+
+// Unwrap returns the traced source, so dotconfig can find its name.
+func (s tracedSource) Unwrap() dotconfig.Source {
+	return s.src
+}
@@ -0,0 +1,64 @@
+package dotconfigotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/DeanPDX/dotconfig"
+	"github.com/DeanPDX/dotconfig/dotconfigotel"
+)
+
+func TestTraceSource(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	src := dotconfigotel.TraceSource(dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"OTEL_REGION": "us-west-2", "OTEL_API_KEY": "abc123"}, nil
+	}), "test")
+	if _, err := src.Fetch(context.Background()); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	failing := dotconfigotel.TraceSource(dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return nil, errors.New("access denied")
+	}), "test")
+	if _, err := failing.Fetch(context.Background()); err == nil {
+		t.Fatalf("Expected error.")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans. Got %v.", len(spans))
+	}
+	expected := []attribute.KeyValue{dotconfigotel.SourceTypeKey.String("test"), dotconfigotel.KeysFetchedKey.Int(2)}
+	attrs := spans[0].Attributes()
+	if len(attrs) != len(expected) || attrs[0] != expected[0] || attrs[1] != expected[1] {
+		t.Errorf("Expected attributes %v. Got %v.", expected, attrs)
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("Expected failed fetch to be marked as an error. Got %v.", spans[1].Status())
+	}
+}
+
+func TestTraceSourceName(t *testing.T) {
+	type namedConfig struct {
+		Password string `env:"OTEL_NAMED_PASSWORD" source:"vault"`
+	}
+	vault := dotconfig.NamedSource("vault", dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"OTEL_NAMED_PASSWORD": "from-vault"}, nil
+	}))
+	t.Setenv("OTEL_NAMED_PASSWORD", "")
+	config, err := dotconfig.FromSource[namedConfig](context.Background(), dotconfigotel.TraceSource(vault, "vault"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Password != "from-vault" {
+		t.Errorf("Expected from-vault. Got %q.", config.Password)
+	}
+}
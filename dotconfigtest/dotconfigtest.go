@@ -0,0 +1,38 @@
+// Package dotconfigtest provides helpers for loading config in tests.
+package dotconfigtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+// Load reads envFile and decodes it into a T, using opts for both.
+// Changes the load makes to the environment are reverted when the test
+// and all its subtests complete. Because of that, Load can't be used in
+// parallel tests unless values are kept out of the process environment
+// with [dotconfig.WithEnviron] or [dotconfig.NoExport].
+//
+// Any error (including a missing file) fails the test immediately:
+//
+//	func TestHandler(t *testing.T) {
+//		conf := dotconfigtest.Load[AppConfig](t, "testdata/.env")
+//		// ...
+//	}
+func Load[T any](t testing.TB, envFile string, opts ...dotconfig.DecodeOption) T {
+	t.Helper()
+	file, err := os.Open(envFile)
+	if err != nil {
+		t.Fatalf("dotconfigtest: opening env file: %v", err)
+	}
+	defer file.Close()
+	// Load and decode in one go, so options that keep values out of the
+	// process environment see the values from the file.
+	config, res, err := dotconfig.LoadWithResult[T](file, opts...)
+	t.Cleanup(res.Unset)
+	if err != nil {
+		t.Fatalf("dotconfigtest: loading %v:\n%v", envFile, err)
+	}
+	return config
+}
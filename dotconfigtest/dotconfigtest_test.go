@@ -0,0 +1,49 @@
+package dotconfigtest_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+	"github.com/DeanPDX/dotconfig/dotconfigtest"
+)
+
+type testConfig struct {
+	Name string `env:"DOTCONFIGTEST_NAME"`
+	Port int    `env:"DOTCONFIGTEST_PORT"`
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		config := dotconfigtest.Load[testConfig](t, "testdata/test.env")
+		if config.Name != "test app" || config.Port != 8080 {
+			t.Fatalf("Unexpected config: %#v", config)
+		}
+	})
+	// Cleanup from the subtest should have removed our keys.
+	if _, ok := os.LookupEnv("DOTCONFIGTEST_NAME"); ok {
+		t.Errorf("Expected DOTCONFIGTEST_NAME to be unset after subtest.")
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	// Options like Profile change how the file is read, not just how
+	// it's decoded.
+	config := dotconfigtest.Load[testConfig](t, "testdata/profile.env", dotconfig.Profile("ci"))
+	if config.Name != "test app" || config.Port != 9090 {
+		t.Fatalf("Unexpected config: %#v", config)
+	}
+}
+
+func TestLoadPrivate(t *testing.T) {
+	// Values kept out of the process environment still reach the config.
+	for _, opt := range []dotconfig.DecodeOption{dotconfig.NoExport, dotconfig.WithEnviron(map[string]string{})} {
+		config := dotconfigtest.Load[testConfig](t, "testdata/test.env", opt)
+		if config.Name != "test app" || config.Port != 8080 {
+			t.Fatalf("Unexpected config: %#v", config)
+		}
+		if _, ok := os.LookupEnv("DOTCONFIGTEST_NAME"); ok {
+			t.Errorf("Expected DOTCONFIGTEST_NAME to stay out of the environment.")
+		}
+	}
+}
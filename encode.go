@@ -0,0 +1,165 @@
+package dotconfig
+
+import (
+	"encoding"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeValue formats v the way decodeValue reads it, so values survive
+// a round trip through the environment. Slices are joined with sep.
+func encodeValue(v reflect.Value, sep string) string {
+	return encode(v, sep, false)
+}
+
+// redactedValue formats v like encodeValue, but with each [Secret] in it
+// replaced by "***", for reports.
+func redactedValue(v reflect.Value, sep string) string {
+	return encode(v, sep, true)
+}
+
+func encode(v reflect.Value, sep string, redact bool) string {
+	// Pointers encode like what they point to, and nil ones like an
+	// unset value.
+	for v.Kind() == reflect.Pointer && v.Type() != locationType {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	// Encoding is for writing values out to be used, so secrets are
+	// revealed unless this is for a report.
+	if isSecretType(v.Type()) {
+		if redact {
+			return redacted
+		}
+		if !v.CanAddr() {
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			v = ptr.Elem()
+		}
+		return encode(v.Addr().Interface().(secretField).valuePtr(), sep, redact)
+	}
+	if isOptionalType(v.Type()) {
+		if !v.CanAddr() {
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			v = ptr.Elem()
+		}
+		opt := v.Addr().Interface().(optionalField)
+		if !opt.IsSet() {
+			return ""
+		}
+		return encode(opt.optionalValue(), sep, redact)
+	}
+	if isSQLNull(v.Type()) {
+		if !v.Field(1).Bool() {
+			return ""
+		}
+		return encode(v.Field(0), sep, redact)
+	}
+	switch v.Type() {
+	case locationType:
+		if v.IsNil() {
+			return ""
+		}
+		return v.Interface().(*time.Location).String()
+	case mailAddressType:
+		addr := v.Interface().(mail.Address)
+		return addr.String()
+	case fileModeType:
+		return fmt.Sprintf("%#o", uint32(v.Interface().(fs.FileMode)))
+	case durationType:
+		return time.Duration(v.Int()).String()
+	}
+	if m, ok := textMarshaler(v); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = encode(v.Index(i), sep, redact)
+		}
+		return strings.Join(parts, sep)
+	}
+	if redact && holdsSecret(v.Type()) {
+		// Maps and the like can't be redacted a piece at a time.
+		return redacted
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// textMarshaler returns v as an [encoding.TextMarshaler] if it or its
+// pointer implements it. Values that can't be addressed are copied so
+// pointer receivers still work.
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return m, true
+	}
+	if !v.CanAddr() {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		v = ptr.Elem()
+	}
+	m, ok := v.Addr().Interface().(encoding.TextMarshaler)
+	return m, ok
+}
+
+// environ returns the env key and encoded value for each field of the
+// struct cv that has an env tag, including secrets.
+func (o options) environ(cv reflect.Value) map[string]string {
+	ct := cv.Type()
+	values := map[string]string{}
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		envKey, _ := o.fieldTag(field)
+		if !field.IsExported() || envKey == "" {
+			continue
+		}
+		for key, value := range o.encodeField(envKey, field, cv.Field(i)) {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// encodeField returns the env keys and encoded values for field, whose
+// value is v, using envKey as its key. [FeatureFlags] fields have a key
+// per flag. [Lazy] fields have none, since encoding them mustn't trigger
+// a fetch.
+func (o options) encodeField(envKey string, field reflect.StructField, v reflect.Value) map[string]string {
+	if isLazyType(field.Type) {
+		return nil
+	}
+	if field.Type == featureFlagsType {
+		values := map[string]string{}
+		for name, on := range v.Interface().(FeatureFlags) {
+			values[envKey+strings.ToUpper(name)] = strconv.FormatBool(on)
+		}
+		return values
+	}
+	return map[string]string{envKey: encodeValue(v, o.separator(field))}
+}
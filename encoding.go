@@ -0,0 +1,47 @@
+package dotconfig
+
+import (
+	"errors"
+	"strings"
+)
+
+// byteOrderMark is added to the start of files by some Windows editors.
+// It's invisible, so without stripping it the first key would silently
+// never match.
+const byteOrderMark = "\ufeff"
+
+var (
+	errByteOrderMark = errors.New("file starts with a byte order mark")
+	errCRLF          = errors.New(`Windows line ending (\r\n)`)
+)
+
+// normalizeLine strips a byte order mark from the first line and the \r
+// from a Windows line ending. With the [StrictEncoding] option, they're
+// errors instead.
+func (o options) normalizeLine(line string, lineNum int) (string, error) {
+	if lineNum == 1 {
+		var bom bool
+		line, bom = strings.CutPrefix(line, byteOrderMark)
+		if bom && o.StrictEncoding {
+			return "", &FieldError{Line: lineNum, Err: ErrMalformedLine, Cause: errByteOrderMark}
+		}
+	}
+	line, crlf := strings.CutSuffix(line, "\r")
+	if crlf && o.StrictEncoding {
+		return "", &FieldError{Line: lineNum, Err: ErrMalformedLine, Cause: errCRLF}
+	}
+	return line, nil
+}
+
+// normalizeSource does what normalizeLine does for a whole file.
+func (o options) normalizeSource(src string) (string, error) {
+	if o.StrictEncoding {
+		for i, line := range strings.Split(src, "\n") {
+			if _, err := o.normalizeLine(line, i+1); err != nil {
+				return "", err
+			}
+		}
+	}
+	src = strings.TrimPrefix(src, byteOrderMark)
+	return strings.ReplaceAll(src, "\r\n", "\n"), nil
+}
@@ -0,0 +1,54 @@
+package dotconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvDir returns a [Source] that reads an envdir directory, the format
+// used by daemontools and runit: each file is named after a key and its
+// contents are the value. A trailing newline is stripped and NUL bytes
+// become newlines, like envdir does:
+//
+//	conf, err := dotconfig.FromSource[AppConfig](ctx, dotconfig.EnvDir("/etc/myapp/env"))
+//
+// Hidden files and directories are skipped, which also makes this work
+// for Kubernetes secrets mounted as volumes, and so are editor backups
+// like KEY~. Empty files are skipped
+// too; envdir uses them to unset a variable, but a [Source] can only set
+// values.
+func EnvDir(dir string) Source {
+	return SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]string{}
+		for _, entry := range entries {
+			if skippedFile(entry.Name()) {
+				continue
+			}
+			// Stat instead of using entry so symlinks are followed.
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				continue
+			}
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			value := strings.TrimSuffix(string(contents), "\n")
+			if value == "" {
+				continue
+			}
+			values[entry.Name()] = strings.ReplaceAll(value, "\x00", "\n")
+		}
+		return values, nil
+	})
+}
@@ -0,0 +1,99 @@
+package dotconfig
+
+import (
+	"os"
+	"strings"
+)
+
+// WithEnviron resolves config against env instead of the process
+// environment, so a decode can't be affected by anything else calling
+// os.Setenv. That makes it safe to load config from parallel tests:
+//
+//	env := dotconfig.WithEnviron(map[string]string{"PORT": "8080"})
+//	conf, err := dotconfig.FromFileName[AppConfig]("testdata/.env", env)
+//
+// env can be a map or KEY=value strings like [os.Environ] returns. Each
+// load starts from a fresh copy of it, and values loaded from files and
+// sources are set in that copy instead of the process environment. So
+// env isn't changed, loads using the same option don't see each other's
+// values, and one option can be shared between loads running at the
+// same time.
+func WithEnviron[E []string | map[string]string](env E) DecodeOption {
+	snapshot := map[string]string{}
+	switch env := any(env).(type) {
+	case []string:
+		for _, kv := range env {
+			if key, value, ok := strings.Cut(kv, "="); ok {
+				snapshot[key] = value
+			}
+		}
+	case map[string]string:
+		for key, value := range env {
+			snapshot[key] = value
+		}
+	}
+	return funcOption(func(o *options) {
+		o.Environ = snapshot
+	})
+}
+
+// getenv looks up key in the environment from [WithEnviron], or the
+// process environment if there isn't one. With [NoExport], values from
+// the load are checked first.
+func (o options) getenv(key string) (string, bool) {
+	if value, ok := o.private[key]; ok {
+		return value, true
+	}
+	if o.Environ != nil {
+		value, ok := o.Environ[key]
+		return value, ok
+	}
+	return os.LookupEnv(key)
+}
+
+// envList returns the environment from [WithEnviron], or the process
+// environment, as KEY=value strings.
+func (o options) envList() []string {
+	if o.Environ == nil && o.private == nil {
+		return os.Environ()
+	}
+	values := map[string]string{}
+	for key, value := range o.Environ {
+		values[key] = value
+	}
+	if o.Environ == nil {
+		for _, kv := range os.Environ() {
+			key, value, _ := strings.Cut(kv, "=")
+			values[key] = value
+		}
+	}
+	for key, value := range o.private {
+		values[key] = value
+	}
+	env := make([]string, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		env = append(env, key+"="+values[key])
+	}
+	return env
+}
+
+// newResult returns an empty [Result] that sets values in the
+// environment from [WithEnviron], or privately with [NoExport].
+func (o options) newResult() Result {
+	if o.private != nil {
+		return Result{env: o.private}
+	}
+	return Result{env: o.Environ}
+}
+
+// export sets key in the process environment for a field tagged export
+// when values are being kept out of it with [NoExport].
+func (o options) export(key string, res *Result) {
+	value, ok := o.private[key]
+	if !ok {
+		return
+	}
+	prev, existed := os.LookupEnv(key)
+	os.Setenv(key, value)
+	res.changes = append(res.changes, envChange{key: key, prev: prev, existed: existed, process: true})
+}
@@ -0,0 +1,43 @@
+package dotconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithPrefix limits the keys decoded into a map[string]string or
+// map[string]any to those that start with prefix:
+//
+//	flags, err := dotconfig.FromFileName[map[string]string](".env", dotconfig.WithPrefix("FF_"))
+//
+// Keys keep their prefix.
+func WithPrefix(prefix string) DecodeOption {
+	return funcOption(func(o *options) {
+		o.Prefix = prefix
+	})
+}
+
+// isEnvMap reports whether t is a map[string]string or map[string]any,
+// which we decode the whole environment into instead of struct fields.
+func isEnvMap(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+	elem := t.Elem()
+	return elem.Kind() == reflect.String ||
+		(elem.Kind() == reflect.Interface && elem.NumMethod() == 0)
+}
+
+// envMap builds a map of type t from the environment. t must satisfy
+// [isEnvMap].
+func envMap(t reflect.Type, opts options) reflect.Value {
+	m := reflect.MakeMap(t)
+	for _, kv := range opts.envList() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, opts.Prefix) {
+			continue
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), reflect.ValueOf(value).Convert(t.Elem()))
+	}
+	return m
+}
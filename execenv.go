@@ -0,0 +1,80 @@
+package dotconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ExecEnv returns fields of config as KEY=value strings for
+// [exec.Cmd.Env], so a launcher can hand a subprocess exactly the
+// config it needs instead of its whole environment. fields maps the
+// names of the Go fields to pass to the key the child should see them
+// as, or to "" to keep the key from the field's env tag:
+//
+//	env, err := dotconfig.ExecEnv(conf, map[string]string{
+//		"DatabaseURL": "",     // DATABASE_URL, like the env tag
+//		"WorkerPort":  "PORT", // renamed for the child
+//	})
+//	cmd := exec.Command("./worker")
+//	cmd.Env = env
+//
+// A nil fields passes every field with an env tag. Values are encoded
+// the way [WriteShell] writes them, so [FeatureFlags] fields pass a key
+// per flag. Secret values are included, since the child needs them.
+// [Lazy] fields are left out, since they may not have been fetched, and
+// naming one in fields is an error. Pass the options you decoded
+// with so keys and separators match. To add to the current environment
+// rather than replace it, use append(os.Environ(), env...).
+func ExecEnv[T any](config T, fields map[string]string, opts ...DecodeOption) ([]string, error) {
+	cv := reflect.ValueOf(&config).Elem()
+	if cv.Kind() != reflect.Struct {
+		return nil, ErrConfigMustBeStruct
+	}
+	ops := optsFromVariadic(opts)
+	ct := cv.Type()
+	values := map[string]string{}
+	found := map[string]bool{}
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		envKey, _ := ops.fieldTag(field)
+		if !field.IsExported() || envKey == "" {
+			continue
+		}
+		key := envKey
+		if fields != nil {
+			rename, ok := fields[field.Name]
+			if !ok {
+				continue
+			}
+			found[field.Name] = true
+			if isLazyType(field.Type) {
+				return nil, fmt.Errorf("%v is Lazy, so it can't be passed", field.Name)
+			}
+			if rename != "" {
+				key = rename
+			}
+		}
+		for key, value := range ops.encodeField(key, field, cv.Field(i)) {
+			if _, ok := values[key]; ok {
+				return nil, fmt.Errorf("more than one field would be passed as %v", key)
+			}
+			values[key] = value
+		}
+	}
+	missing := []string{}
+	for name := range fields {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%v has no field with an env tag named %v", ct, missing[0])
+	}
+	env := make([]string, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		env = append(env, key+"="+values[key])
+	}
+	return env, nil
+}
@@ -0,0 +1,72 @@
+package dotconfig
+
+import "strings"
+
+// expand replaces ${VAR} in s with the value of VAR from the
+// environment, or an empty string if it isn't set. Unlike [os.ExpandEnv],
+// a bare $VAR is left alone since dollar signs are common in passwords.
+// An unterminated "${" is left as-is.
+func (o options) expand(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		value, _ := o.getenv(s[start+2 : start+end])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// expandPercent replaces %VAR% in s with the value of VAR from the
+// environment, the way Windows batch files do. %% is a literal percent
+// sign. References to unset variables and percent signs that don't
+// surround a variable name, like "50% off", are left as-is.
+func (o options) expandPercent(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '%')
+		if start < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		s = s[start+1:]
+		if strings.HasPrefix(s, "%") {
+			b.WriteByte('%')
+			s = s[1:]
+			continue
+		}
+		end := strings.IndexByte(s, '%')
+		if end <= 0 || !isVariableName(s[:end]) {
+			b.WriteByte('%')
+			continue
+		}
+		if value, ok := o.getenv(s[:end]); ok {
+			b.WriteString(value)
+		} else {
+			b.WriteString("%" + s[:end] + "%")
+		}
+		s = s[end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// isVariableName reports whether name is made up of letters, digits
+// and underscores.
+func isVariableName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if !isSpecNameByte(name[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package dotconfig
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// redacted replaces the values of secret fields in reports.
+const redacted = "***"
+
+// Origin describes where a field's value came from.
+type Origin string
+
+const (
+	OriginFlag    Origin = "flag"    // Set by a command-line flag. See [WithFlags].
+	OriginEnv     Origin = "env"     // Set from the environment or an env file.
+	OriginDefault Origin = "default" // Set from a `default` struct tag.
+	OriginUnset   Origin = "unset"   // Not set anywhere.
+)
+
+// sourceOrigin returns the origin for a value from source, a name from
+// keySource or sourceDefault.
+func sourceOrigin(source string) Origin {
+	switch source {
+	case SourceFlag:
+		return OriginFlag
+	case sourceDefault:
+		return OriginDefault
+	}
+	return OriginEnv
+}
+
+// WithResult reports the origins recorded in res by [Explain] and
+// [DebugHandler], instead of working them out from the environment as
+// it is when they're called:
+//
+//	conf, res, err := dotconfig.LoadWithResult[AppConfig](file, dotconfig.NoExport)
+//	// ...
+//	mux.Handle("/debug/config", dotconfig.DebugHandler(store.Get, dotconfig.WithResult(res)))
+func WithResult(res Result) DecodeOption {
+	return funcOption(func(o *options) {
+		o.Origins = res.Origins
+	})
+}
+
+// FieldInfo describes a single field of a config struct. See [Explain].
+type FieldInfo struct {
+	Field  string `json:"field"`
+	Key    string `json:"key"`
+	Desc   string `json:"desc,omitempty"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
+	Origin Origin `json:"origin"`
+}
+
+// Explain describes each field in config that has an env tag: its key,
+// its desc tag, its current value, and where that value came from.
+// Fields tagged `env:"KEY,secret"` have their values redacted, so the
+// result is safe to log or serve from a debug endpoint. Origins are
+// only exact with [WithResult]. Otherwise they're worked out from the
+// environment now, which is only right if it hasn't changed since the
+// load and the same options are passed. It can't see values loaded with
+// [NoExport], for one. If config
+// is a pointer, the struct it points to is described, which is how to
+// explain a config whose type isn't known until runtime. If config is
+// not a struct, Explain returns nil.
+func Explain[T any](config T, opts ...DecodeOption) []FieldInfo {
+	ops := optsFromVariadic(opts)
+	cv := reflect.ValueOf(&config).Elem()
+	if cv.Kind() == reflect.Interface {
+		cv = cv.Elem()
+	}
+	if cv.Kind() == reflect.Pointer && !cv.IsNil() {
+		cv = cv.Elem()
+	}
+	if cv.Kind() != reflect.Struct {
+		return nil
+	}
+	ct := cv.Type()
+	var infos []FieldInfo
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		envKey, tagOpts := ops.fieldTag(field)
+		if !field.IsExported() || envKey == "" {
+			continue
+		}
+		info := FieldInfo{
+			Field:  field.Name,
+			Key:    envKey,
+			Desc:   field.Tag.Get("desc"),
+			Value:  redactedValue(cv.Field(i), ops.separator(field)),
+			Secret: tagOpts.Contains("secret") || holdsSecret(field.Type),
+			Origin: ops.origin(envKey, field),
+		}
+		if tagOpts.Contains("secret") {
+			info.Value = redacted
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// origin reports where the value for key came from, as recorded by
+// [WithResult], or else where it would come from now.
+func (o options) origin(key string, field reflect.StructField) Origin {
+	if origin, ok := o.Origins[field.Name]; ok {
+		return origin
+	}
+	if _, ok := o.lookupFlag(key); ok {
+		return OriginFlag
+	}
+	if _, ok := o.lookupEnv(key); ok {
+		return OriginEnv
+	}
+	if _, _, ok := o.lookupDeprecated(deprecatedKeys(field)); ok {
+		return OriginEnv
+	}
+	if _, tagOpts := o.fieldTag(field); tagOpts != "" {
+		if from, ok := tagOpts.Value("from"); ok && from != "" {
+			if _, ok := o.lookupEnv(from); ok {
+				return OriginEnv
+			}
+		}
+	}
+	if _, ok := o.fieldDefault(field); ok {
+		return OriginDefault
+	}
+	return OriginUnset
+}
+
+// MarshalJSON returns the resolved values of config as a JSON object of
+// env keys to values, with secret fields redacted like they are by
+// [Explain]. It's meant for dashboards, debug bundles and support
+// tickets:
+//
+//	{"API_KEY":"***","MAX_BYTES":"1024","REGION":"us-west-2"}
+//
+// Values are formatted the way they'd be written in an env file.
+func MarshalJSON[T any](config T, opts ...DecodeOption) ([]byte, error) {
+	if reflect.TypeOf(config) == nil || reflect.TypeOf(config).Kind() != reflect.Struct {
+		return nil, ErrConfigMustBeStruct
+	}
+	values := map[string]string{}
+	for _, info := range Explain(config, opts...) {
+		values[info.Key] = info.Value
+	}
+	return json.Marshal(values)
+}
@@ -0,0 +1,64 @@
+package dotconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FeatureFlags holds on/off switches read from every key with a common
+// prefix. A FeatureFlags field's env key is the prefix, and the rest of
+// each key, in lower case, is the flag name:
+//
+//	type AppConfig struct {
+//		Features dotconfig.FeatureFlags `env:"FF_"`
+//	}
+//
+//	// FF_NEW_BILLING=on
+//	if conf.Features.Enabled("new_billing") {
+//		// ...
+//	}
+//
+// Values can be anything [ExtendedBools] accepts, like true, 1 or on,
+// and an empty value is off. No keys with the prefix is fine too: every
+// flag is off.
+type FeatureFlags map[string]bool
+
+// Enabled reports whether the flag name is on. Names aren't case
+// sensitive and flags that weren't set are off.
+func (f FeatureFlags) Enabled(name string) bool {
+	return f[strings.ToLower(name)]
+}
+
+var featureFlagsType = reflect.TypeOf(FeatureFlags(nil))
+
+// featureFlags reads the flags for a [FeatureFlags] field from every
+// key that starts with prefix. It returns the keys it read so they
+// aren't reported as unknown, even if one of them is invalid.
+func (o options) featureFlags(prefix string) (FeatureFlags, []string, *FieldError) {
+	flags := FeatureFlags{}
+	var keys []string
+	var fieldErr *FieldError
+	for _, kv := range o.envList() {
+		key, value, _ := strings.Cut(kv, "=")
+		name := strings.TrimPrefix(key, prefix)
+		if name == key || name == "" {
+			continue
+		}
+		keys = append(keys, key)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			flags[strings.ToLower(name)] = false
+			continue
+		}
+		on, err := parseExtendedBool(value)
+		if err != nil {
+			if fieldErr == nil {
+				fieldErr = o.invalidValue(value, err, "")
+				fieldErr.Key = key
+			}
+			continue
+		}
+		flags[strings.ToLower(name)] = on
+	}
+	return flags, keys, fieldErr
+}
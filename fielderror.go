@@ -0,0 +1,146 @@
+package dotconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxErrors caps the number of errors returned when decoding at n. If
+// there are more, the error message ends with "and N more". Errors are
+// always reported in struct field order, so the same config and
+// environment always produce the same error text.
+func MaxErrors(n int) DecodeOption {
+	return funcOption(func(o *options) {
+		o.MaxErrors = n
+	})
+}
+
+// FieldError describes a problem with a single field or key. Errors
+// returned when decoding are FieldErrors, so handlers can find out which
+// key failed and why without parsing error strings:
+//
+//	for _, err := range dotconfig.Errors(err) {
+//		var fieldErr *dotconfig.FieldError
+//		if errors.As(err, &fieldErr) {
+//			log.Printf("bad config for %v on line %v", fieldErr.Key, fieldErr.Line)
+//		}
+//	}
+type FieldError struct {
+	// Field is the name of the struct field, if any.
+	Field string
+	// Path is the full path to the field from the config struct, like
+	// Stripe.WebhookSecret for a field of a nested struct. For fields
+	// of the config struct itself it's the same as Field.
+	Path string
+	// Key is the env key from the field's env tag, if any.
+	Key string
+	// Desc is the field's desc tag, if any.
+	Desc string
+	// Line is the line of the env file the value came from, or 0 if it
+	// didn't come from a file.
+	Line int
+	// Offset is the byte offset of the start of Line, for errors about
+	// the syntax of the file itself. It's 0 otherwise.
+	Offset int64
+	// Err is one of the sentinel errors like [ErrMissingEnvVar].
+	Err error
+	// Cause is the underlying problem, such as a parse error, if any.
+	Cause error
+}
+
+// Error formats the error as Err followed by the key (or field path if
+// there's no key, or line number if there's neither) and then Cause.
+// Keys include the desc tag because a bare key name often means nothing
+// to the operator who has to fix it. [ErrUnsupportedFieldType] errors
+// are just Err and Cause, the type, which is how they've always read.
+func (e *FieldError) Error() string {
+	if e.Err == ErrUnsupportedFieldType && e.Cause != nil {
+		return fmt.Sprintf("%v: %v", e.Err, e.Cause)
+	}
+	subject := e.Key
+	if subject == "" {
+		subject = e.Path
+	}
+	if subject == "" {
+		subject = e.Field
+	}
+	if subject == "" && e.Line > 0 {
+		subject = fmt.Sprintf("line %d", e.Line)
+	}
+	if e.Desc != "" {
+		subject += " — " + e.Desc
+	}
+	msg := fmt.Sprintf("%v: %v", e.Err, subject)
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// copy returns a pointer to a copy of e. decodeEnv fills in a
+// FieldError for every field, and copying it only when there's an error
+// keeps it from being allocated on every load.
+func (e FieldError) copy() *FieldError {
+	return &e
+}
+
+// Unwrap returns Err so [errors.Is] works with the sentinel errors.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// errorKinds maps sentinel errors to the kind reported by [ErrorsJSON].
+var errorKinds = []struct {
+	err  error
+	kind string
+}{
+	{ErrConfigMustBeStruct, "config_must_be_struct"},
+	{ErrMissingStructTag, "missing_struct_tag"},
+	{ErrMissingEnvVar, "missing_env_var"},
+	{ErrUnsupportedFieldType, "unsupported_field_type"},
+	{ErrInvalidValue, "invalid_value"},
+	{ErrInvalidTag, "invalid_tag"},
+	{ErrMalformedLine, "malformed_line"},
+	{ErrLoosePermissions, "loose_permissions"},
+}
+
+// jsonError is the JSON representation of an error from [ErrorsJSON].
+type jsonError struct {
+	Kind    string `json:"kind"`
+	Field   string `json:"field,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Key     string `json:"key,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// ErrorsJSON serializes the errors in err (see [Errors]) to a JSON array
+// so CI pipelines and deploy tooling can consume them without parsing
+// error strings. Each element looks like:
+//
+//	{"kind":"missing_env_var","field":"SMTPHost","path":"SMTPHost","key":"SMTP_HOST","message":"value not present in env: SMTP_HOST"}
+//
+// kind is one of config_must_be_struct, missing_struct_tag,
+// missing_env_var, unsupported_field_type, invalid_value, invalid_tag,
+// malformed_line, loose_permissions, or unknown.
+// If err is nil, the result is an empty array.
+func ErrorsJSON(err error) ([]byte, error) {
+	errs := Errors(err)
+	out := make([]jsonError, 0, len(errs))
+	for _, err := range errs {
+		je := jsonError{Kind: "unknown", Message: err.Error()}
+		for _, k := range errorKinds {
+			if errors.Is(err, k.err) {
+				je.Kind = k.kind
+				break
+			}
+		}
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			je.Field, je.Path, je.Key, je.Line = fieldErr.Field, fieldErr.Path, fieldErr.Key, fieldErr.Line
+		}
+		out = append(out, je)
+	}
+	return json.Marshal(out)
+}
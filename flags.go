@@ -0,0 +1,126 @@
+package dotconfig
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegisterFlags defines a string flag on fs for each field in T that has
+// an env tag. Flag names are the env key in lower case with underscores
+// replaced by dashes, so MAX_BYTES_PER_REQUEST becomes
+// -max-bytes-per-request. Flags for bool and *bool fields can be passed
+// without a value, like -debug. The desc tag, if any, is used as the
+// flag's usage. Use [WithFlags] when decoding so values passed on the
+// command line take precedence over the environment:
+//
+//	dotconfig.RegisterFlags[AppConfig](flag.CommandLine)
+//	flag.Parse()
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.WithFlags(flag.CommandLine))
+//
+// Pass the options that change how tags are read, like
+// [Caarlos0Compat], so flags get the same keys as the decode.
+func RegisterFlags[T any](fs *flag.FlagSet, opts ...DecodeOption) error {
+	var config T
+	ct := reflect.TypeOf(config)
+	if ct == nil || ct.Kind() != reflect.Struct {
+		return ErrConfigMustBeStruct
+	}
+	ops := optsFromVariadic(opts)
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		envKey, _ := ops.fieldTag(field)
+		if !field.IsExported() || envKey == "" {
+			continue
+		}
+		name := flagName(envKey)
+		if fs.Lookup(name) != nil {
+			continue
+		}
+		usage := fmt.Sprintf("overrides %v", envKey)
+		if desc := field.Tag.Get("desc"); desc != "" {
+			usage = fmt.Sprintf("%v (overrides %v)", desc, envKey)
+		}
+		if isBoolField(field.Type) {
+			fs.Var(new(boolFlag), name, usage)
+			continue
+		}
+		fs.String(name, "", usage)
+	}
+	return nil
+}
+
+// isBoolField reports whether fields of type t get a boolean flag.
+func isBoolField(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
+// boolFlag is the flag for a bool field. It holds the value as given,
+// like a string flag, so it's decoded like any other value, but it can
+// be passed without one.
+type boolFlag string
+
+func (b *boolFlag) String() string {
+	return string(*b)
+}
+
+func (b *boolFlag) Set(value string) error {
+	*b = boolFlag(value)
+	return nil
+}
+
+// IsBoolFlag tells the flag package that -name means -name=true.
+func (b *boolFlag) IsBoolFlag() bool {
+	return true
+}
+
+// WithFlags makes flags set on fs take precedence over the environment.
+// Only flags that were actually passed on the command line are used, so
+// an existing [flag.FlagSet] can be imported as long as its flag names
+// follow the naming convention described in [RegisterFlags]. Call
+// fs.Parse before decoding.
+func WithFlags(fs *flag.FlagSet) DecodeOption {
+	return funcOption(func(o *options) {
+		o.Flags = fs
+	})
+}
+
+// flagName converts an env key like MAX_BYTES to a flag name like
+// max-bytes.
+func flagName(envKey string) string {
+	return strings.ToLower(strings.ReplaceAll(envKey, "_", "-"))
+}
+
+// lookupEnv returns the value for key, checking flags that were set on
+// the command line before the environment. With [EmptyAsMissing], empty
+// values are reported as not set.
+func (o options) lookupEnv(key string) (string, bool) {
+	value, ok := o.lookupFlag(key)
+	if !ok {
+		value, ok = o.getenv(key)
+	}
+	if ok && o.EmptyAsMissing && strings.TrimSpace(value) == "" {
+		return "", false
+	}
+	return value, ok
+}
+
+// lookupFlag returns the value of the flag for key if it was set on the
+// command line.
+func (o options) lookupFlag(key string) (string, bool) {
+	if o.Flags == nil {
+		return "", false
+	}
+	name := flagName(key)
+	value, set := "", false
+	o.Flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			value, set = f.Value.String(), true
+		}
+	})
+	return value, set
+}
@@ -0,0 +1,73 @@
+package dotconfig_test
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+func TestFlags(t *testing.T) {
+	type flagConfig struct {
+		Host string `env:"FLAG_HOST"`
+		Port int    `env:"FLAG_PORT"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := dotconfig.RegisterFlags[flagConfig](fs); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if err := fs.Parse([]string{"-flag-port=9090"}); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	r := strings.NewReader("FLAG_HOST=localhost\nFLAG_PORT=8080")
+	config, err := dotconfig.FromReader[flagConfig](r, dotconfig.WithFlags(fs))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := flagConfig{Host: "localhost", Port: 9090}
+	if config != expected {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestBoolFlags(t *testing.T) {
+	type boolFlagConfig struct {
+		Debug   bool  `env:"FLAG_DEBUG"`
+		Verbose *bool `env:"FLAG_VERBOSE"`
+		Color   bool  `env:"FLAG_COLOR"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := dotconfig.RegisterFlags[boolFlagConfig](fs); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if err := fs.Parse([]string{"-flag-debug", "-flag-verbose", "-flag-color=false", "extra"}); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if fs.NArg() != 1 {
+		t.Errorf("Expected 1 argument. Got %v.", fs.Args())
+	}
+	r := strings.NewReader("FLAG_COLOR=true")
+	config, err := dotconfig.FromReader[boolFlagConfig](r, dotconfig.WithFlags(fs), dotconfig.WithEnviron(map[string]string{}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if !config.Debug || config.Verbose == nil || !*config.Verbose || config.Color {
+		t.Errorf("Expected debug and verbose but not color. Got %#v.", config)
+	}
+}
+
+func TestFlagsCompat(t *testing.T) {
+	// Flag names come from the same keys the decode uses, even without
+	// env tags.
+	type compatFlagConfig struct {
+		MaxBytes int `split_words:"true"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := dotconfig.RegisterFlags[compatFlagConfig](fs, dotconfig.EnvconfigCompat("app")); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if fs.Lookup("app-max-bytes") == nil {
+		t.Errorf("Expected app-max-bytes flag.")
+	}
+}
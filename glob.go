@@ -0,0 +1,87 @@
+package dotconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FromGlob loads every file matching pattern (see [filepath.Match] for the
+// syntax) in lexical order, so values in later files override earlier
+// ones, and then decodes a T:
+//
+//	conf, err := dotconfig.FromGlob[AppConfig]("config/*.env")
+//
+// If nothing matches, values come from the environment like they do
+// when [FromFileName] can't find its file.
+func FromGlob[T any](pattern string, opts ...DecodeOption) (T, error) {
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		var config T
+		return config, err
+	}
+	return fromFiles[T](names, optsFromVariadic(opts))
+}
+
+// FromDirAll loads every regular file in dir in lexical order and then
+// decodes a T. This is the conf.d pattern: prefix files with numbers,
+// like 10-base.env and 20-local.env, to control which ones win. Hidden
+// files and editor backups, like .app.env.swp and app.env~, are skipped
+// like they are by [EnvDir].
+//
+// If dir doesn't exist, values come from the environment unless the
+// [ReturnFileIOErrors] option is set.
+func FromDirAll[T any](dir string, opts ...DecodeOption) (T, error) {
+	ops := optsFromVariadic(opts)
+	entries, err := os.ReadDir(dir)
+	if err != nil && ops.ReturnFileIOErrors {
+		var config T
+		return config, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !skippedFile(entry.Name()) {
+			names = append(names, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return fromFiles[T](names, ops)
+}
+
+// skippedFile reports whether a file in a directory of config should be
+// left out because it's hidden, like .env.swp, or an editor's backup or
+// autosave, like app.env~ or #app.env#.
+func skippedFile(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") ||
+		(len(name) > 1 && strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#"))
+}
+
+// fromFiles loads each of the files in order and then decodes a T.
+func fromFiles[T any](names []string, ops options) (T, error) {
+	res := ops.newResult()
+	for _, name := range names {
+		if err := loadFile(name, ops, &res); err != nil {
+			ops.reportWarnings(&res)
+			var config T
+			return config, err
+		}
+	}
+	config, err := fromEnv[T](ops, &res)
+	if err != nil {
+		return config, err
+	}
+	if err := ops.removeAfterLoad(names...); err != nil {
+		var zero T
+		return zero, err
+	}
+	return config, nil
+}
+
+// loadFile opens name and loads it into the environment.
+func loadFile(name string, ops options, res *Result) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return load(file, ops, res)
+}
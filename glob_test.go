@@ -0,0 +1,88 @@
+package dotconfig_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+type globConfig struct {
+	Region   string `env:"GLOB_REGION"`
+	LogLevel string `env:"GLOB_LOG_LEVEL"`
+}
+
+// writeConfDir writes a conf.d style directory where 20-local.env
+// overrides a value from 10-base.env.
+func writeConfDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-base.env":  "GLOB_REGION=us-west-2\nGLOB_LOG_LEVEL=info\n",
+		"20-local.env": "GLOB_LOG_LEVEL=debug\n",
+		"README":       "GLOB_REGION=ignored-by-glob\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFromGlob(t *testing.T) {
+	dir := writeConfDir(t)
+	config, err := dotconfig.FromGlob[globConfig](filepath.Join(dir, "*.env"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := globConfig{Region: "us-west-2", LogLevel: "debug"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestFromDirAll(t *testing.T) {
+	dir := writeConfDir(t)
+	// README sorts last, so it wins.
+	config, err := dotconfig.FromDirAll[globConfig](dir)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := globConfig{Region: "ignored-by-glob", LogLevel: "debug"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	_, err = dotconfig.FromDirAll[globConfig](filepath.Join(dir, "missing"), dotconfig.ReturnFileIOErrors)
+	if err == nil {
+		t.Errorf("Expected error for missing directory.")
+	}
+
+	// Hidden files and editor backups sort after 10-base.env but don't
+	// override it.
+	dir = t.TempDir()
+	files := map[string]string{
+		"10-base.env":   "GLOB_REGION=us-west-2\nGLOB_LOG_LEVEL=info\n",
+		".env.swp":      "GLOB_LOG_LEVEL=swap\n",
+		"10-base.env~":  "GLOB_LOG_LEVEL=backup\n",
+		"#10-base.env#": "GLOB_LOG_LEVEL=autosave\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config, err = dotconfig.FromDirAll[globConfig](dir)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected = globConfig{Region: "us-west-2", LogLevel: "info"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
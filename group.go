@@ -0,0 +1,51 @@
+package dotconfig
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// activeGroups returns the groups from group tags on the fields of ct
+// that have at least one key set, mapped to the first such key. Fields
+// in a group are all-or-nothing, like `group:"smtp"` on SMTP_HOST,
+// SMTP_PORT and SMTP_PASSWORD: if any of them is set, the others are
+// required too, unless they're tagged optional or have a default.
+func (o options) activeGroups(ct reflect.Type) map[string]string {
+	active := map[string]string{}
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		group := strings.TrimSpace(field.Tag.Get("group"))
+		if group == "" || active[group] != "" {
+			continue
+		}
+		envKey, _ := o.fieldTag(field)
+		if envKey == "" {
+			continue
+		}
+		if _, ok := o.lookupEnv(envKey); ok {
+			active[group] = envKey
+		}
+	}
+	return active
+}
+
+// checkGroup looks for problems with a field's group tag.
+func checkGroup(field reflect.StructField, tagOpts tagOptions) error {
+	group, ok := field.Tag.Lookup("group")
+	if !ok {
+		return nil
+	}
+	if strings.TrimSpace(group) == "" {
+		return errors.New("group can't be empty")
+	}
+	if _, ok := field.Tag.Lookup("requiredif"); ok {
+		return errors.New("group fields can't also have requiredif")
+	}
+	for _, name := range []string{"required", "present"} {
+		if tagOpts.Contains(name) {
+			return errors.New("group fields can't also be " + name)
+		}
+	}
+	return nil
+}
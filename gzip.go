@@ -0,0 +1,78 @@
+package dotconfig
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// defaultMaxDecompressedSize is the most a gzipped env file can
+// decompress to without the [MaxDecompressedSize] option.
+const defaultMaxDecompressedSize = 16 << 20
+
+// MaxDecompressedSize sets the most, in bytes, that a gzipped env file
+// can decompress to. The default is 16MB, which is far more than any
+// env file needs but stops a small file that decompresses to gigabytes
+// from using up memory:
+//
+//	entries, err := dotconfig.Parse(r, dotconfig.MaxDecompressedSize(1<<20))
+//
+// Going over the limit is a [ParseError]. A negative n means there's no
+// limit, for files you trust.
+func MaxDecompressedSize(n int64) DecodeOption {
+	return funcOption(func(o *options) {
+		o.MaxDecompressedSize = n
+	})
+}
+
+// decompress returns a reader for the decompressed contents of r if it's
+// gzipped, like a .env.gz bundle, and otherwise r's contents as is. We
+// go by the magic bytes rather than the file name so readers work too.
+// Decompressed contents are limited by [MaxDecompressedSize].
+func (o options) decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	max := o.MaxDecompressedSize
+	if max == 0 {
+		max = defaultMaxDecompressedSize
+	}
+	if max < 0 {
+		return zr, nil
+	}
+	return &limitReader{r: zr, n: max, max: max}, nil
+}
+
+// limitReader is like [io.LimitReader], but reading past the limit is an
+// error instead of the end of the file, so a file that's too big can't
+// be mistaken for a shorter one.
+type limitReader struct {
+	r io.Reader
+	// n is how many more bytes can be read.
+	n   int64
+	max int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	// Reading one byte past the limit tells a file that's exactly the
+	// limit from one that's over it.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		return int(l.n), fmt.Errorf("gzip: decompresses to more than %v bytes", l.max)
+	}
+	l.n -= int64(n)
+	return n, err
+}
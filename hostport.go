@@ -0,0 +1,50 @@
+package dotconfig
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// HostPort is a listen or dial address like "db.internal:5432" or
+// ":8080". It's checked and split when the config is loaded, so a
+// missing or bad port fails at startup instead of when the listener or
+// first connection does:
+//
+//	type AppConfig struct {
+//		ListenAddr dotconfig.HostPort `env:"LISTEN_ADDR"`
+//	}
+//
+//	http.ListenAndServe(conf.ListenAddr.String(), nil)
+//
+// The host can be empty, which means all interfaces when listening.
+// IPv6 hosts have to be in brackets, like "[::1]:8080". The port has to
+// be a number from 0 to 65535.
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// String joins the host and port back into an address.
+func (h HostPort) String() string {
+	return net.JoinHostPort(h.Host, strconv.Itoa(h.Port))
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (h HostPort) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (h *HostPort) UnmarshalText(text []byte) error {
+	host, port, err := net.SplitHostPort(string(text))
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	*h = HostPort{Host: host, Port: int(n)}
+	return nil
+}
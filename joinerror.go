@@ -10,6 +10,8 @@ import (
 //   - https://cs.opensource.google/go/go/+/refs/tags/go1.23.1:src/errors/join.go;l=40
 type joinError struct {
 	errs []error
+	// omitted is the number of errors dropped by limit.
+	omitted int
 }
 
 // HasErrors will return true if any of the errors in underlying
@@ -30,6 +32,15 @@ func (je *joinError) Add(err error) {
 	}
 }
 
+// limit drops all but the first n errors, keeping count of how many
+// were dropped. n <= 0 means no limit.
+func (je *joinError) limit(n int) {
+	if n > 0 && len(je.errs) > n {
+		je.omitted += len(je.errs) - n
+		je.errs = je.errs[:n]
+	}
+}
+
 // Error implements the error interface
 func (je joinError) Error() string {
 	// We have no errors
@@ -37,7 +48,7 @@ func (je joinError) Error() string {
 		return ""
 	}
 	// If we have a single error, just return it.
-	if len(je.errs) == 1 {
+	if len(je.errs) == 1 && je.omitted == 0 {
 		return je.errs[0].Error()
 	}
 	// We have multiple errors, so build up a nice error string.
@@ -45,9 +56,19 @@ func (je joinError) Error() string {
 	for i, err := range je.errs {
 		errorStrings[i] = err.Error()
 	}
+	if je.omitted > 0 {
+		errorStrings = append(errorStrings, fmt.Sprintf("and %d more", je.omitted))
+	}
 	return fmt.Sprintf("multiple errors:\n- %s", strings.Join(errorStrings, "\n- "))
 }
 
+// Unwrap returns the underlying errors so [errors.Is] and [errors.As]
+// can inspect each of them. See the Go 1.20 release notes on wrapping
+// multiple errors.
+func (je joinError) Unwrap() []error {
+	return je.errs
+}
+
 // Errors returns a slice containing zero or more errors that the supplied
 // error is composed of. If the error is nil, a nil slice is returned.
 //
@@ -0,0 +1,78 @@
+package dotconfig
+
+import (
+	"errors"
+	"strings"
+)
+
+// errInvalidKey explains why a key was rejected with [RejectInvalidKeys].
+var errInvalidKey = errors.New("keys must match [A-Za-z_][A-Za-z0-9_]*")
+
+// validKey reports whether key is a portable environment variable name:
+// letters, digits and underscores, not starting with a digit. Other
+// names can be set, but shells and many tools can't read them back.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c == '_', 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeKey turns key into a valid key for [SanitizeKeys] by replacing
+// invalid characters with underscores, so app.name becomes app_name. A
+// leading digit gets an underscore in front of it.
+func sanitizeKey(key string) string {
+	var b strings.Builder
+	for i, c := range key {
+		switch {
+		case c == '_', 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z':
+			b.WriteRune(c)
+		case '0' <= c && c <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// checkKeys looks for entries with invalid keys before anything is set.
+// By default they're set as is with a warning. With [SanitizeKeys] they
+// are renamed and with [RejectInvalidKeys] they're errors.
+func (o options) checkKeys(entries []Entry, res *Result) ([]Entry, error) {
+	errs := joinError{}
+	checked := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if validKey(entry.Key) {
+			checked = append(checked, entry)
+			continue
+		}
+		switch {
+		case o.RejectInvalidKeys:
+			errs.Add(&FieldError{Key: entry.Key, Line: entry.Line, Err: ErrMalformedLine, Cause: errInvalidKey})
+			continue
+		case o.SanitizeKeys && entry.Key != "":
+			key := sanitizeKey(entry.Key)
+			res.warn(WarnInvalidKey, entry.Key, entry.Line, "%q isn't a valid key, using %v", entry.Key, key)
+			entry.Key = key
+		default:
+			res.warn(WarnInvalidKey, entry.Key, entry.Line, "%q isn't a valid key", entry.Key)
+		}
+		checked = append(checked, entry)
+	}
+	if errs.HasErrors() {
+		return nil, errs
+	}
+	return checked, nil
+}
@@ -0,0 +1,77 @@
+package dotconfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WriteManifests writes values to w as a Kubernetes ConfigMap and Secret
+// called name, so a local .env file can be the one source of truth for
+// a deployment too. Keys of secret fields in T (tagged
+// `env:"KEY,secret"` or of type [Secret]) go in the Secret and
+// everything else goes in the ConfigMap:
+//
+//	entries, err := dotconfig.Parse(file)
+//	// ...
+//	values := map[string]string{}
+//	for _, entry := range entries {
+//		values[entry.Key] = entry.Value
+//	}
+//	err = dotconfig.WriteManifests[AppConfig](os.Stdout, "myapp", values)
+//
+// Either manifest is left out if it would be empty. Load both into a pod
+// with envFrom.
+func WriteManifests[T any](w io.Writer, name string, values map[string]string, opts ...DecodeOption) error {
+	var config T
+	ct := reflect.TypeOf(config)
+	if ct == nil || ct.Kind() != reflect.Struct {
+		return ErrConfigMustBeStruct
+	}
+	ops := optsFromVariadic(opts)
+	secretKeys := map[string]bool{}
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		envKey, tagOpts := ops.fieldTag(field)
+		if envKey != "" && (tagOpts.Contains("secret") || holdsSecret(field.Type)) {
+			secretKeys[envKey] = true
+		}
+	}
+	var configData, secretData strings.Builder
+	for _, key := range sortedKeys(values) {
+		if secretKeys[key] {
+			fmt.Fprintf(&secretData, "  %v: %v\n", strconv.Quote(key), strconv.Quote(base64.StdEncoding.EncodeToString([]byte(values[key]))))
+		} else {
+			fmt.Fprintf(&configData, "  %v: %v\n", strconv.Quote(key), strconv.Quote(values[key]))
+		}
+	}
+	var manifests []string
+	if configData.Len() > 0 {
+		manifests = append(manifests, manifest("ConfigMap", name, "", configData.String()))
+	}
+	if secretData.Len() > 0 {
+		manifests = append(manifests, manifest("Secret", name, "type: Opaque\n", secretData.String()))
+	}
+	_, err := io.WriteString(w, strings.Join(manifests, "---\n"))
+	return err
+}
+
+// WriteConfigManifests calls [WriteManifests] with the value of each
+// field in config that has an env tag.
+func WriteConfigManifests[T any](w io.Writer, name string, config T, opts ...DecodeOption) error {
+	cv := reflect.ValueOf(&config).Elem()
+	if cv.Kind() != reflect.Struct {
+		return ErrConfigMustBeStruct
+	}
+	return WriteManifests[T](w, name, optsFromVariadic(opts).environ(cv), opts...)
+}
+
+// manifest returns the YAML for a ConfigMap or Secret. Strings are
+// quoted with [strconv.Quote], which produces valid YAML double quoted
+// strings.
+func manifest(kind, name, extra, data string) string {
+	return fmt.Sprintf("apiVersion: v1\nkind: %v\nmetadata:\n  name: %v\n%vdata:\n%v", kind, strconv.Quote(name), extra, data)
+}
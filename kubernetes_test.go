@@ -0,0 +1,69 @@
+package dotconfig_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+type manifestConfig struct {
+	Region string `env:"REGION"`
+	APIKey string `env:"API_KEY,secret"`
+	Motd   string `env:"MOTD"`
+}
+
+func TestWriteManifests(t *testing.T) {
+	var buf bytes.Buffer
+	values := map[string]string{"REGION": "us-west-2", "API_KEY": "abc123", "EXTRA": "not in struct"}
+	if err := dotconfig.WriteManifests[manifestConfig](&buf, "myapp", values); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "myapp"
+data:
+  "EXTRA": "not in struct"
+  "REGION": "us-west-2"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: "myapp"
+type: Opaque
+data:
+  "API_KEY": "YWJjMTIz"
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, buf.String())
+	}
+}
+
+func TestWriteConfigManifests(t *testing.T) {
+	var buf bytes.Buffer
+	config := manifestConfig{Region: "us-west-2", Motd: "Hello,\n\"world\""}
+	if err := dotconfig.WriteConfigManifests(&buf, "myapp", config); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	// An empty secret value still goes in the Secret.
+	expected := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "myapp"
+data:
+  "MOTD": "Hello,\n\"world\""
+  "REGION": "us-west-2"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: "myapp"
+type: Opaque
+data:
+  "API_KEY": ""
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, buf.String())
+	}
+}
@@ -0,0 +1,162 @@
+package dotconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Lazy is a config field whose value isn't looked up until the first
+// call to [Lazy.Get], so startup doesn't wait on secrets that are only
+// used by rarely exercised code paths. Use it with the [LazySource]
+// option to fetch the value from a [Source]:
+//
+//	type AppConfig struct {
+//		Region        string                  `env:"REGION"`
+//		ReportingKey  dotconfig.Lazy[string]  `env:"REPORTING_KEY,secret"`
+//	}
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.LazySource(vault))
+//	// Later, when a report is requested:
+//	key, err := conf.ReportingKey.Get()
+//
+// Without LazySource, the value comes from the environment at the time
+// of the first Get. Tags like default and optional work as usual, but
+// errors are returned from Get instead of when decoding. Copies of a
+// Lazy share their value, so it's only fetched once.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	mu      sync.Mutex
+	done    bool
+	value   T
+	resolve func(v reflect.Value) error
+}
+
+// errLazyUnbound is returned by Get for a Lazy that wasn't decoded.
+var errLazyUnbound = errors.New("dotconfig: Lazy value wasn't decoded")
+
+// Get returns the value, looking it up the first time it's called. If
+// the lookup fails, the error is returned and the next call tries again.
+func (l Lazy[T]) Get() (T, error) {
+	if l.state == nil {
+		var value T
+		return value, errLazyUnbound
+	}
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+	if !l.state.done {
+		var value T
+		if err := l.state.resolve(reflect.ValueOf(&value).Elem()); err != nil {
+			return value, err
+		}
+		l.state.value, l.state.done = value, true
+	}
+	return l.state.value, nil
+}
+
+// String returns the value if it has been looked up, so reports like
+// [Explain] don't trigger a fetch.
+func (l Lazy[T]) String() string {
+	if l.state == nil {
+		return ""
+	}
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+	if !l.state.done {
+		return "(not fetched)"
+	}
+	return fmt.Sprint(l.state.value)
+}
+
+func (l *Lazy[T]) bind(resolve func(v reflect.Value) error) {
+	l.state = &lazyState[T]{resolve: resolve}
+}
+
+func (l *Lazy[T]) valueType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// lazyField is implemented by pointers to [Lazy] fields.
+type lazyField interface {
+	bind(resolve func(v reflect.Value) error)
+	valueType() reflect.Type
+}
+
+var lazyFieldType = reflect.TypeOf((*lazyField)(nil)).Elem()
+
+// isLazyType reports whether t is a [Lazy].
+func isLazyType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(lazyFieldType)
+}
+
+// LazySource sets the [Source] that [Lazy] fields are fetched from.
+func LazySource(src Source) DecodeOption {
+	return funcOption(func(o *options) {
+		o.LazySource = src
+	})
+}
+
+// lazyResolver returns the function a [Lazy] field uses to look up and
+// decode its value.
+func (o options) lazyResolver(field reflect.StructField, envKey string, tagOpts tagOptions) func(v reflect.Value) error {
+	return func(v reflect.Value) error {
+		fieldErr := FieldError{Field: field.Name, Path: field.Name, Key: envKey, Desc: field.Tag.Get("desc")}
+		value, ok, err := o.lazyLookup(envKey)
+		if err != nil {
+			return err
+		}
+		if from, hasFrom := tagOpts.Value("from"); hasFrom {
+			resolved, found, err := o.resolveFrom(from)
+			if found && ok {
+				err = fmt.Errorf("%v and %v are both set", envKey, from)
+			}
+			if err != nil {
+				fieldErr.Err, fieldErr.Cause = ErrInvalidValue, err
+				return &fieldErr
+			}
+			if found {
+				value, ok = resolved, true
+			}
+		}
+		if !ok {
+			defaultValue, hasDefault := o.fieldDefault(field)
+			switch {
+			case hasDefault:
+				value = defaultValue
+			case tagOpts.Contains("optional"):
+				return nil
+			default:
+				fieldErr.Err = ErrMissingEnvVar
+				return &fieldErr
+			}
+		}
+		if value == "" {
+			return nil
+		}
+		if err := o.decodeValue(v, value, tagOpts, o.separator(field)); err != nil {
+			fieldErr.Err, fieldErr.Cause = err.Err, err.Cause
+			return &fieldErr
+		}
+		return nil
+	}
+}
+
+// lazyLookup looks up key in the [LazySource], or the environment if
+// there isn't one.
+func (o options) lazyLookup(key string) (string, bool, error) {
+	if o.LazySource == nil {
+		value, ok := o.lookupEnv(key)
+		return value, ok, nil
+	}
+	values, err := o.fetch(context.Background(), o.LazySource)
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := values[key]
+	return value, ok, nil
+}
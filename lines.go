@@ -0,0 +1,204 @@
+package dotconfig
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultMaxLineLength is the longest line read without the
+// [MaxLineLength] option, the same as [bufio.Scanner]'s default.
+const defaultMaxLineLength = bufio.MaxScanTokenSize
+
+// lineReader reads an env file a line at a time, keeping track of where
+// each line starts so errors can point at it. It's a small state
+// machine over the bytes of the file: blank lines and comments that
+// can't hold directives are skipped without being read into memory, so
+// they can be any length, and other lines are only buffered as far as
+// they go, not up to the limit.
+type lineReader struct {
+	r   *bufio.Reader
+	max int
+	// prefixes are the prefixes from CommentPrefixes that lines can be
+	// skipped for.
+	prefixes []string
+	buf      []byte
+	// num and offset are the line number and byte offset of the line
+	// last returned by next or nextRaw.
+	num    int
+	offset int64
+	// next is the offset of the line after it.
+	nextOffset int64
+}
+
+// newLineReader returns a lineReader for r using the limit from
+// [MaxLineLength] and the prefixes from [CommentPrefixes] in o.
+func newLineReader(r io.Reader, o options) *lineReader {
+	l := &lineReader{r: bufio.NewReader(r), max: o.MaxLineLength}
+	if l.max == 0 {
+		l.max = defaultMaxLineLength
+	}
+	for _, prefix := range o.CommentPrefixes {
+		// Include directives are checked before comments, so lines
+		// that could be one have to be read.
+		if prefix != "" && len(prefix) < l.r.Size() && !strings.HasPrefix(prefix, "source") && !strings.HasPrefix("source", prefix) {
+			l.prefixes = append(l.prefixes, prefix)
+		}
+	}
+	return l
+}
+
+// next returns the next line without its "\n". A "\r" before it is
+// left for normalizeLine to deal with. Skipped lines come back as just
+// what they start with, like "#" for a comment, so the caller sees the
+// same kind of line. At the end of the file it returns io.EOF, and for a
+// line longer than the limit it returns [bufio.ErrTooLong] with num and
+// offset set to that line.
+func (l *lineReader) next() (string, error) {
+	l.start()
+	if l.num == 1 {
+		if b, _ := l.r.Peek(len(byteOrderMark)); string(b) == byteOrderMark {
+			l.buf = append(l.buf, b...)
+			l.discard(len(b))
+		}
+	}
+	// Leading whitespace is trimmed from every line, so there's no need
+	// to keep it.
+	for {
+		b, err := l.r.ReadByte()
+		if err == io.EOF && l.nextOffset > l.offset {
+			// The last line doesn't need a newline.
+			return string(l.buf), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if b != ' ' && b != '\t' {
+			l.r.UnreadByte()
+			break
+		}
+		l.nextOffset++
+	}
+	if b, _ := l.r.Peek(1); b[0] == '\n' {
+		l.discard(1)
+		return string(l.buf), nil
+	}
+	if prefix, ok := l.comment(); ok {
+		crlf, err := l.skipLine()
+		if err != nil {
+			return "", err
+		}
+		line := string(l.buf) + prefix
+		if crlf {
+			line += "\r"
+		}
+		return line, nil
+	}
+	return l.readLine()
+}
+
+// nextRaw returns the next line as is, for the lines of a quoted value
+// that spans several lines, where nothing is a comment.
+func (l *lineReader) nextRaw() (string, error) {
+	l.start()
+	return l.readLine()
+}
+
+// start moves on to a new line.
+func (l *lineReader) start() {
+	l.buf = l.buf[:0]
+	l.num++
+	l.offset = l.nextOffset
+}
+
+func (l *lineReader) discard(n int) {
+	n, _ = l.r.Discard(n)
+	l.nextOffset += int64(n)
+}
+
+// comment reports whether the line at the reader is a comment that can
+// be skipped, and returns the prefix it starts with. Comments that might
+// hold schema or include directives, or that start with whitespace
+// other than spaces and tabs, have to be read like any other line.
+func (l *lineReader) comment() (string, bool) {
+	if b, _ := l.r.Peek(1); b[0] != '#' {
+		for _, prefix := range l.prefixes {
+			if b, _ := l.r.Peek(len(prefix)); string(b) == prefix {
+				return prefix, true
+			}
+		}
+		return "", false
+	}
+	for n := 1; ; n++ {
+		b, err := l.r.Peek(n + len(directivePrefix))
+		if err != nil && err != io.EOF {
+			return "", false
+		}
+		if len(b) <= n {
+			// The file ends in the comment.
+			return "#", true
+		}
+		if b[n] == ' ' || b[n] == '\t' {
+			continue
+		}
+		r, _ := utf8.DecodeRune(b[n:])
+		if r != '\n' && unicode.IsSpace(r) {
+			return "", false
+		}
+		if bytes.HasPrefix(b[n:], []byte(directivePrefix)) || bytes.HasPrefix(b[n:], []byte("include")) {
+			return "", false
+		}
+		return "#", true
+	}
+}
+
+// skipLine discards the rest of the line and reports whether it ended
+// with "\r".
+func (l *lineReader) skipLine() (bool, error) {
+	var last byte
+	for {
+		chunk, err := l.r.ReadSlice('\n')
+		l.nextOffset += int64(len(chunk))
+		if err == nil {
+			chunk = chunk[:len(chunk)-1]
+		}
+		if len(chunk) > 0 {
+			last = chunk[len(chunk)-1]
+		}
+		switch {
+		case err == nil, err == io.EOF:
+			return last == '\r', nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		default:
+			return false, err
+		}
+	}
+}
+
+// readLine reads the rest of the line into buf and returns it.
+func (l *lineReader) readLine() (string, error) {
+	for {
+		chunk, err := l.r.ReadSlice('\n')
+		l.buf = append(l.buf, chunk...)
+		l.nextOffset += int64(len(chunk))
+		if l.max >= 0 && (len(l.buf) > l.max+1 || (len(l.buf) > l.max && l.buf[len(l.buf)-1] != '\n')) {
+			return "", bufio.ErrTooLong
+		}
+		switch {
+		case err == nil:
+			return string(l.buf[:len(l.buf)-1]), nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err == io.EOF && len(l.buf) > 0:
+			// The last line doesn't need a newline.
+			return string(l.buf), nil
+		default:
+			return "", err
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package dotconfig
+
+import "time"
+
+// Metrics holds callbacks for events worth counting in production, so
+// you can alert when a service is quietly running on defaults it
+// shouldn't be. Any of them can be nil:
+//
+//	defaults := promauto.NewCounterVec(prometheus.CounterOpts{Name: "config_defaults_applied_total"}, []string{"key"})
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.WithMetrics(dotconfig.Metrics{
+//		DefaultApplied: func(field, key string) {
+//			defaults.WithLabelValues(key).Inc()
+//		},
+//	}))
+type Metrics struct {
+	// DefaultApplied is called when a field is set from its default
+	// tag because key wasn't set.
+	DefaultApplied func(field, key string)
+	// OptionalMissing is called when key isn't set for an optional
+	// field, so it's left as is.
+	OptionalMissing func(field, key string)
+	// DeprecatedKeyUsed is called when a field is set from oldKey in
+	// its deprecated tag because key wasn't set.
+	DeprecatedKeyUsed func(oldKey, key string)
+	// SourceFetched is called after each fetch from a [Source],
+	// including any retries, with how long it took and the error if it
+	// failed. source is the name from [NamedSource].
+	SourceFetched func(source string, d time.Duration, err error)
+}
+
+// WithMetrics calls the callbacks in m while loading.
+func WithMetrics(m Metrics) DecodeOption {
+	return funcOption(func(o *options) {
+		o.Metrics = m
+	})
+}
+
+func (m Metrics) defaultApplied(field, key string) {
+	if m.DefaultApplied != nil {
+		m.DefaultApplied(field, key)
+	}
+}
+
+func (m Metrics) optionalMissing(field, key string) {
+	if m.OptionalMissing != nil {
+		m.OptionalMissing(field, key)
+	}
+}
+
+func (m Metrics) deprecatedKeyUsed(oldKey, key string) {
+	if m.DeprecatedKeyUsed != nil {
+		m.DeprecatedKeyUsed(oldKey, key)
+	}
+}
+
+func (m Metrics) sourceFetched(source string, d time.Duration, err error) {
+	if m.SourceFetched != nil {
+		m.SourceFetched(source, d, err)
+	}
+}
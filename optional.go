@@ -0,0 +1,75 @@
+package dotconfig
+
+import "reflect"
+
+// Optional holds a value that may not have been set, as a nicer
+// alternative to pointer fields for tri-state config. Fields of type
+// Optional are optional unless tagged required, and record whether
+// their key was present:
+//
+//	type AppConfig struct {
+//		MaxConns dotconfig.Optional[int] `env:"MAX_CONNS"`
+//	}
+//
+//	if conf.MaxConns.IsSet() {
+//		pool.SetMaxConns(conf.MaxConns.Value())
+//	}
+//
+// A key set to an empty value (MAX_CONNS=) counts as set, with the zero
+// value. So does a value from a default tag.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an [Optional] that is set to value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// IsSet reports whether the value was set.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// Value returns the value, which is the zero value of T if it isn't set.
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+// Or returns the value if it's set and fallback otherwise.
+func (o Optional[T]) Or(fallback T) T {
+	if o.set {
+		return o.value
+	}
+	return fallback
+}
+
+func (o *Optional[T]) markSet() {
+	o.set = true
+}
+
+func (o *Optional[T]) optionalValue() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+// optionalField is implemented by pointers to [Optional] fields.
+type optionalField interface {
+	IsSet() bool
+	markSet()
+	optionalValue() reflect.Value
+}
+
+var optionalFieldType = reflect.TypeOf((*optionalField)(nil)).Elem()
+
+// isOptionalType reports whether t is an [Optional].
+func isOptionalType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(optionalFieldType)
+}
+
+// implicitlyOptional reports whether fields of type t can be missing
+// without being tagged optional, because t can tell the difference
+// between missing and set itself.
+func implicitlyOptional(t reflect.Type) bool {
+	return isSQLNull(t) || isOptionalType(t)
+}
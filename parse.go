@@ -0,0 +1,605 @@
+package dotconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a single KEY=VALUE pair read by [Parse].
+type Entry struct {
+	Key   string
+	Value string
+	// Raw is the value exactly as it appeared in the file: everything
+	// after the "=", with quotes, escapes, whitespace and any trailing
+	// comment left in. It's for tools that need to round-trip a file or
+	// point at exactly what was written.
+	Raw string
+	// Line is the 1-based line number the entry was read from.
+	Line int
+	// Append is true for KEY+=value lines, which add to the key's
+	// existing value instead of replacing it.
+	Append bool
+	// File is the path of the included file the entry was read from, or
+	// empty if it came from the reader passed to [Parse].
+	File string
+	// Schema holds directives from "# dotconfig:" comments directly
+	// above the entry.
+	Schema Schema
+	// Profile is the section the entry was read from, like "dev" for
+	// entries after a [dev] line. It's empty for entries in [default]
+	// or before any section. See [Profile].
+	Profile string
+}
+
+// Schema holds directives from structured comments in an env file.
+// Directives go in a comment directly above the key they describe:
+//
+//	# dotconfig: required, type=int, desc=Max request size in bytes
+//	MAX_BYTES_PER_REQUEST=1024
+//
+// Supported directives are required, type (one of string, int, float,
+// bool, or duration) and desc. Since desc is free text, it must be last
+// and can contain commas. See [ValidateEntries] for enforcing them.
+type Schema struct {
+	Required bool
+	Type     string
+	Desc     string
+}
+
+// directivePrefix starts a comment with schema directives.
+const directivePrefix = "dotconfig:"
+
+// Parse reads key/value pairs from r without setting anything in the
+// environment. This is useful for tools that need to inspect env files,
+// like linters and doc generators.
+//
+// The syntax, which [FromReader] also uses, is line based:
+//
+//	file    = { line ( "\n" | EOF ) } .
+//	line    = blank | comment | include | section | pair | other .
+//	blank   = { " " | "\t" } .
+//	comment = "#" { char } .
+//	include = ( "# include" | "source" ) spaces name .
+//	section = "[" name "]" .
+//	pair    = key [ "+" ] "=" ( value [ " #" { char } ] | multi ) .
+//	value   = quote { char } quote | { char } .
+//	multi   = quote { char } "\n" { char | "\n" } quote [ { " " } comment ] .
+//	quote   = "'" | `"` .
+//
+// Lines are trimmed of surrounding whitespace before they're matched, a
+// byte order mark at the start of the file and a "\r" at the end of a
+// line are dropped, and "\n" in a value is a newline. The key is
+// everything before the first "=". A value that opens a quote without
+// closing it on the same line goes on, newlines and all, until the next
+// line with that quote, and the lines in between aren't comments.
+// Comments starting "# dotconfig:" hold [Schema] directives, names in
+// includes and sections can't contain spaces, and other lines, which
+// have no "=", are skipped. Other syntaxes can be chosen with
+// [ComposeEnvFile] and [DotenvSpec].
+//
+// Parse doesn't panic, whatever r holds. If it can't read r, the error
+// is a [*ParseError] and the entries are the ones read before the
+// problem. Lines longer than [MaxLineLength] are errors that match
+// [bufio.ErrTooLong] with [errors.Is]. Gzipped input that decompresses
+// to more than [MaxDecompressedSize] is an error too.
+//
+// The options that change how files are read are used: [ComposeEnvFile],
+// [DotenvSpec], [MaxLineLength], [MaxDecompressedSize],
+// [CommentPrefixes], [FollowIncludes], [NoIncludes] and [WithEnviron].
+// Others are ignored. Parse never reads
+// the process environment, so with [DotenvSpec] variables only expand to
+// values from earlier lines or [WithEnviron]. Include directives are
+// comments unless [FollowIncludes] is passed, so files from people you
+// don't trust can't read other files on the machine:
+//
+//	entries, err := dotconfig.Parse(upload, dotconfig.MaxLineLength(4096))
+func Parse(r io.Reader, opts ...DecodeOption) (entries []Entry, err error) {
+	defer func() {
+		// Parsing is meant to handle anything, but the file may be from
+		// someone else and a crash would take down the program reading
+		// it, so a bug here is reported as an error instead.
+		if p := recover(); p != nil {
+			entries, err = nil, &ParseError{Err: fmt.Errorf("internal error: %v", p)}
+		}
+	}()
+	all := optsFromVariadic(opts)
+	o := options{
+		Syntax:              all.Syntax,
+		MaxLineLength:       all.MaxLineLength,
+		CommentPrefixes:     all.CommentPrefixes,
+		MaxDecompressedSize: all.MaxDecompressedSize,
+		NoIncludes:          all.NoIncludes || !all.FollowIncludes,
+		Environ:             all.Environ,
+	}
+	if o.Environ == nil {
+		o.Environ = map[string]string{}
+	}
+	entries, _, err = parse(r, o)
+	if _, ok := err.(*ParseError); err != nil && !ok {
+		err = &ParseError{Err: err}
+	}
+	return entries, err
+}
+
+// ParseError is the error [Parse] returns when it can't read an env
+// file, because a line is too long, an include failed or the reader
+// returned an error. Errors from [FromReader] and the other loaders
+// can wrap one too.
+type ParseError struct {
+	// File is the path of the included file the problem is in, or
+	// empty if it's in the reader passed to [Parse].
+	File string
+	// Line is the 1-based line the problem is on, or 0 if it isn't
+	// about a particular line, like a corrupt gzip stream.
+	Line int
+	// Offset is the byte offset of the start of Line, or 0 if it isn't
+	// known. [DotenvSpec] errors don't have offsets.
+	Offset int64
+	// Err is the underlying problem, like [bufio.ErrTooLong].
+	Err error
+}
+
+// Error formats the error as the file and line followed by Err, like
+// "line 2 (byte 18): bufio.Scanner: token too long".
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File + ": ")
+	}
+	switch {
+	case e.Offset > 0:
+		fmt.Fprintf(&b, "line %v (byte %v): ", e.Line, e.Offset)
+	case e.Line > 0:
+		fmt.Fprintf(&b, "line %v: ", e.Line)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns Err, so errors.Is(err, bufio.ErrTooLong) works.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// malformedLines turns warnings about malformed lines into
+// [ErrMalformedLine] errors for the [StrictSyntax] option. It returns
+// the other warnings.
+func malformedLines(warnings []Warning) ([]Warning, error) {
+	errs := joinError{}
+	var rest []Warning
+	for _, w := range warnings {
+		if w.Kind != WarnMalformedLine {
+			rest = append(rest, w)
+			continue
+		}
+		errs.Add(&FieldError{Line: w.Line, Offset: w.Offset, Err: ErrMalformedLine, Cause: errors.New("expected KEY=VALUE")})
+	}
+	if errs.HasErrors() {
+		return rest, errs
+	}
+	return rest, nil
+}
+
+// syntax is the set of rules used to parse env files.
+type syntax int
+
+const (
+	syntaxDotconfig syntax = iota // Quotes, escapes, comments and includes as described in [FromReader]
+	syntaxCompose                 // Everything after "=" is the value, see [ComposeEnvFile]
+	syntaxSpec                    // Ruby and Node dotenv rules, see [DotenvSpec]
+)
+
+// maxIncludeDepth limits how deeply include directives can be nested.
+const maxIncludeDepth = 10
+
+// parse implements [Parse] and also returns warnings about lines that
+// were skipped or changed. If r is a file, includes are relative to it.
+// Only the parsing options in o are used, like o.Syntax.
+func parse(r io.Reader, o options) ([]Entry, []Warning, error) {
+	var stack []string
+	name := "env file"
+	if f, ok := r.(interface{ Name() string }); ok {
+		name = f.Name()
+		if path, err := filepath.Abs(name); err == nil {
+			stack = []string{path}
+		}
+	}
+	warnings, err := o.checkPermissions(r, name)
+	if err != nil {
+		return nil, warnings, err
+	}
+	if r, err = o.decompress(r); err != nil {
+		return nil, warnings, err
+	}
+	var (
+		entries []Entry
+		parsed  []Warning
+	)
+	if o.Syntax == syntaxSpec {
+		entries, parsed, err = parseSpec(r, o)
+	} else {
+		entries, parsed, err = parseIncludes(r, stack, o)
+	}
+	return entries, append(warnings, parsed...), err
+}
+
+// parseIncludes parses r, which was included by the files in stack (the
+// last of which is r itself, if it's a file).
+func parseIncludes(r io.Reader, stack []string, o options) ([]Entry, []Warning, error) {
+	var (
+		entries  []Entry
+		warnings []Warning
+		schema   Schema
+		profile  string
+	)
+	lines := newLineReader(r, o)
+	for {
+		text, err := lines.next()
+		if err == io.EOF {
+			break
+		}
+		lineNum := lines.num
+		if err != nil {
+			// A line longer than the limit stops us early, so don't
+			// pretend we read the whole file.
+			if errors.Is(err, bufio.ErrTooLong) {
+				err = fmt.Errorf("%w (see dotconfig.MaxLineLength)", err)
+			}
+			return entries, warnings, &ParseError{Line: lineNum, Offset: lines.offset, Err: err}
+		}
+		raw, err := o.normalizeLine(text, lineNum)
+		if err != nil {
+			if fieldErr, ok := err.(*FieldError); ok {
+				fieldErr.Offset = lines.offset
+			}
+			return entries, warnings, err
+		}
+		line := strings.TrimSpace(raw)
+		// Empty line means any directives we've seen aren't attached
+		// to a key.
+		if len(line) == 0 {
+			schema = Schema{}
+			continue
+		}
+		// Include directives pull in the entries from another file at
+		// this point, so later lines can override them.
+		if name, ok := includeDirective(line); ok && o.Syntax != syntaxCompose && !o.NoIncludes {
+			included, includedWarnings, err := include(name, stack, o)
+			if err != nil {
+				return entries, warnings, &ParseError{Line: lineNum, Offset: lines.offset, Err: err}
+			}
+			for _, entry := range included {
+				if entry.Profile == "" {
+					entry.Profile = profile
+				}
+				entries = append(entries, entry)
+			}
+			warnings = append(warnings, includedWarnings...)
+			schema = Schema{}
+			continue
+		}
+		// Comments, which may contain directives for the next key.
+		if strings.HasPrefix(line, "#") {
+			comment := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			if directives, ok := strings.CutPrefix(comment, directivePrefix); ok {
+				for _, msg := range schema.parse(directives) {
+					warnings = append(warnings, Warning{Kind: WarnUnknownDirective, Line: lineNum, Message: msg})
+				}
+			}
+			continue
+		}
+		// Comments using other conventions, see [CommentPrefixes].
+		if o.isComment(line) {
+			continue
+		}
+		// Section headers like [dev] start a profile. See [Profile].
+		if name, ok := sectionHeader(line); ok && o.Syntax != syntaxCompose {
+			profile = name
+			schema = Schema{}
+			continue
+		}
+		// Otherwise, if it doesn't have "=" we don't have a valid line.
+		if !strings.Contains(line, "=") {
+			warnings = append(warnings, Warning{Kind: WarnMalformedLine, Line: lineNum, Offset: lines.offset, Message: "skipped line with no '='"})
+			continue
+		}
+
+		// Turn a line into key/value pair. Example lines:
+		// STRIPE_SECRET_KEY='sk_test_asDF!'
+		// STRIPE_SECRET_KEY=sk_test_asDF!
+		// STRIPE_SECRET_KEY="sk_test_asDF!"
+		key := line[0:strings.Index(line, "=")]
+		value := line[len(key)+1:]
+		_, rawValue, _ := strings.Cut(raw, "=")
+		if o.Syntax == syntaxCompose {
+			entries = append(entries, Entry{Key: strings.TrimSpace(key), Value: value, Raw: rawValue, Line: lineNum, Schema: schema, Profile: profile})
+			schema = Schema{}
+			continue
+		}
+		// KEY+=value appends to an earlier value instead of replacing it.
+		key, isAppend := strings.CutSuffix(key, "+")
+
+		// A value with an opening quote and no closing one goes on
+		// until the line that has it.
+		if len(rawValue) > 0 && (rawValue[0] == '\'' || rawValue[0] == '"') && !strings.Contains(rawValue[1:], rawValue[:1]) {
+			start := lines.offset
+			value, rawValue, rest, err := readQuoted(lines, o, rawValue)
+			if err != nil {
+				if fieldErr, ok := err.(*FieldError); ok {
+					fieldErr.Offset = lines.offset
+					return entries, warnings, err
+				}
+				return entries, warnings, &ParseError{Line: lineNum, Offset: start, Err: fmt.Errorf("%v: %w", key, err)}
+			}
+			if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+				warnings = append(warnings, Warning{Kind: WarnMalformedLine, Key: key, Line: lines.num, Offset: lines.offset, Message: fmt.Sprintf("text after the closing quote of %v ignored", key)})
+			}
+			value = strings.ReplaceAll(value, `\n`, "\n")
+			entries = append(entries, Entry{Key: key, Value: value, Raw: rawValue, Line: lineNum, Append: isAppend, Schema: schema, Profile: profile})
+			schema = Schema{}
+			continue
+		}
+
+		// If there is a inline commend, so a space and then a #, exclude the commend.
+		if strings.Contains(value, " #") {
+			value = value[0:strings.Index(value, " #")]
+		} else if !strings.HasPrefix(value, "'") && !strings.HasPrefix(value, `"`) && strings.TrimRight(raw, " \t") != raw {
+			// Trailing whitespace on an unquoted value is easy to add by
+			// accident and impossible to see, so let people know we
+			// dropped it.
+			warnings = append(warnings, Warning{Kind: WarnWhitespaceTrimmed, Key: key, Line: lineNum, Message: fmt.Sprintf("trailing whitespace trimmed from %v", key)})
+		}
+
+		// Determine if our string is single quoted, double quoted, or just raw value.
+		if strings.HasPrefix(value, "'") {
+			// Trim closing single quote
+			value = strings.TrimSuffix(value, "'")
+			// And trim starting single quote
+			value = strings.TrimPrefix(value, "'")
+		} else if strings.HasPrefix(value, `"`) {
+			// Trim closing double quote
+			value = strings.TrimSuffix(value, `"`)
+			// And trim starting double quote
+			value = strings.TrimPrefix(value, `"`)
+		}
+		// Turn \n into newlines
+		value = strings.ReplaceAll(value, `\n`, "\n")
+		entries = append(entries, Entry{Key: key, Value: value, Raw: rawValue, Line: lineNum, Append: isAppend, Schema: schema, Profile: profile})
+		schema = Schema{}
+	}
+	return entries, warnings, nil
+}
+
+// readQuoted reads the rest of a quoted value that spans several lines,
+// given first, the text after the "=" on its first line. It returns the
+// value without its quotes, the raw text including them and whatever
+// follows the closing quote on its line. The whole value counts as one
+// line for [MaxLineLength].
+func readQuoted(lines *lineReader, o options, first string) (value, raw, rest string, err error) {
+	quote := first[:1]
+	var b strings.Builder
+	b.WriteString(first)
+	for {
+		text, err := lines.nextRaw()
+		if err == io.EOF {
+			return "", "", "", fmt.Errorf("%w: no closing %v", ErrMalformedLine, quote)
+		}
+		if err == nil {
+			text, err = o.normalizeLine(text, lines.num)
+		}
+		if err == nil && lines.max >= 0 && b.Len()+1+len(text) > lines.max {
+			err = bufio.ErrTooLong
+		}
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = fmt.Errorf("%w (see dotconfig.MaxLineLength)", err)
+		}
+		if err != nil {
+			return "", "", "", err
+		}
+		b.WriteString("\n")
+		end, rest, ok := strings.Cut(text, quote)
+		if !ok {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString(end)
+		value = b.String()[1:]
+		b.WriteString(quote + rest)
+		return value, b.String(), rest, nil
+	}
+}
+
+// includeDirective reports whether line is "# include name" or
+// "source name" and returns the name. Names are bare file names, with
+// no spaces, quotes, "=" or shell syntax, so ordinary comments like
+// "# include your API key" and lines like "source x=y" aren't
+// directives.
+func includeDirective(line string) (string, bool) {
+	keyword := "source"
+	if comment, ok := strings.CutPrefix(line, "#"); ok {
+		line, keyword = comment, "include"
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 2 || fields[0] != keyword || strings.ContainsAny(fields[1], "=\"'`$;&|<>()") {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// CommentPrefixes adds to the prefixes that start a comment line, for
+// files produced by tools with other conventions:
+//
+//	conf, err := dotconfig.FromFileName[AppConfig]("app.conf", dotconfig.CommentPrefixes(";", "//"))
+//
+// "#" always starts a comment. Only whole lines can be comments with
+// the extra prefixes, and they can't hold schema or include directives.
+func CommentPrefixes(prefixes ...string) DecodeOption {
+	return funcOption(func(o *options) {
+		o.CommentPrefixes = append(o.CommentPrefixes, prefixes...)
+	})
+}
+
+// isComment reports whether line starts with one of the extra prefixes
+// from [CommentPrefixes].
+func (o options) isComment(line string) bool {
+	for _, prefix := range o.CommentPrefixes {
+		if prefix != "" && strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxLineLength sets the longest line, in bytes, that can be read from
+// an env file. The default is 64KB, which isn't enough for things like
+// base64 encoded certificates or large JWKS documents:
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.MaxLineLength(1<<20))
+//
+// Longer lines are an error rather than the end of the file. A quoted
+// value that spans several lines counts as one line, and comments don't
+// count at all since they're skipped without being read into memory. A
+// negative n means there's no limit, for files you trust.
+func MaxLineLength(n int) DecodeOption {
+	return funcOption(func(o *options) {
+		o.MaxLineLength = n
+	})
+}
+
+// defaultProfile is the section for entries that apply to every profile.
+const defaultProfile = "default"
+
+// sectionHeader reports whether line is a section header like [dev] and
+// returns the profile name, which is empty for [default].
+func sectionHeader(line string) (string, bool) {
+	name, ok := strings.CutPrefix(line, "[")
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutSuffix(name, "]")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, "=[]") {
+		return "", false
+	}
+	if name == defaultProfile {
+		name = ""
+	}
+	return name, true
+}
+
+// include parses the file called name, relative to the last file in
+// stack (or the working directory if there isn't one).
+func include(name string, stack []string, o options) ([]Entry, []Warning, error) {
+	if !filepath.IsAbs(name) && len(stack) > 0 {
+		name = filepath.Join(filepath.Dir(stack[len(stack)-1]), name)
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if slices.Contains(stack, path) {
+		return nil, nil, fmt.Errorf("include cycle: %v", strings.Join(append(stack, path), " -> "))
+	}
+	if len(stack) >= maxIncludeDepth {
+		return nil, nil, fmt.Errorf("including %v: includes nested more than %v deep", name, maxIncludeDepth)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("including %v: %w", name, err)
+	}
+	defer f.Close()
+	warnings, err := o.checkPermissions(f, name)
+	if err != nil {
+		return nil, warnings, err
+	}
+	r, err := o.decompress(f)
+	if err != nil {
+		return nil, warnings, fmt.Errorf("including %v: %w", name, err)
+	}
+	entries, parsed, err := parseIncludes(r, append(slices.Clip(stack), path), o)
+	if parseErr, ok := err.(*ParseError); ok && parseErr.File == "" {
+		parseErr.File = path
+	}
+	warnings = append(warnings, parsed...)
+	for i := range entries {
+		if entries[i].File == "" {
+			entries[i].File = path
+		}
+	}
+	return entries, warnings, err
+}
+
+// parse adds the comma-separated directives to s and returns warnings
+// for any it doesn't understand.
+func (s *Schema) parse(directives string) []string {
+	var warnings []string
+	rest := directives
+	for rest != "" {
+		var directive string
+		directive, rest, _ = strings.Cut(rest, ",")
+		directive = strings.TrimSpace(directive)
+		name, value, _ := strings.Cut(directive, "=")
+		switch strings.TrimSpace(name) {
+		case "":
+		case "required":
+			s.Required = true
+		case "type":
+			s.Type = strings.TrimSpace(value)
+		case "desc":
+			// Descriptions are free text, so they get the rest of the line.
+			if rest != "" {
+				value += "," + rest
+				rest = ""
+			}
+			s.Desc = strings.TrimSpace(value)
+		default:
+			warnings = append(warnings, fmt.Sprintf("unknown dotconfig directive %q", name))
+		}
+	}
+	return warnings
+}
+
+// ValidateEntries checks entries against their [Schema] directives:
+// required entries must have a non-empty value, and values must parse as
+// their declared type. Problems are returned as [ErrInvalidValue] errors.
+func ValidateEntries(entries []Entry) error {
+	errs := joinError{}
+	for _, entry := range entries {
+		if entry.Schema.Required && strings.TrimSpace(entry.Value) == "" {
+			errs.Add(&FieldError{Key: entry.Key, Line: entry.Line, Err: ErrInvalidValue, Cause: errors.New("required value is empty")})
+			continue
+		}
+		if entry.Value == "" {
+			continue
+		}
+		var err error
+		switch entry.Schema.Type {
+		case "", "string":
+		case "int":
+			_, err = strconv.ParseInt(entry.Value, 10, 64)
+		case "float":
+			_, err = strconv.ParseFloat(entry.Value, 64)
+		case "bool":
+			_, err = strconv.ParseBool(entry.Value)
+		case "duration":
+			_, err = time.ParseDuration(entry.Value)
+		default:
+			err = fmt.Errorf("unknown type %q", entry.Schema.Type)
+		}
+		if err != nil {
+			errs.Add(&FieldError{Key: entry.Key, Line: entry.Line, Err: ErrInvalidValue, Cause: err})
+		}
+	}
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
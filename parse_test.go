@@ -0,0 +1,622 @@
+package dotconfig_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+const schemaEnv = `# dotconfig: required, type=int, desc=Max request size, in bytes
+MAX_BYTES=1024
+# dotconfig: type=bool
+IS_DEV=maybe
+
+# This directive is separated by a blank line so it's not attached.
+# dotconfig: required
+
+# dotconfig: required
+API_KEY=
+`
+
+func TestParseSchema(t *testing.T) {
+	entries, err := dotconfig.Parse(strings.NewReader(schemaEnv))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []dotconfig.Entry{
+		{Key: "MAX_BYTES", Value: "1024", Raw: "1024", Line: 2, Schema: dotconfig.Schema{Required: true, Type: "int", Desc: "Max request size, in bytes"}},
+		{Key: "IS_DEV", Value: "maybe", Raw: "maybe", Line: 4, Schema: dotconfig.Schema{Type: "bool"}},
+		{Key: "API_KEY", Value: "", Line: 10, Schema: dotconfig.Schema{Required: true}},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, entries)
+	}
+	errs := dotconfig.Errors(dotconfig.ValidateEntries(entries))
+	expectedErrs := []string{
+		`invalid value: IS_DEV: strconv.ParseBool: parsing "maybe": invalid syntax`,
+		"invalid value: API_KEY: required value is empty",
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expectedErrs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expectedErrs[i] {
+			t.Errorf("Expected %q. Got %q.", expectedErrs[i], err)
+		}
+	}
+}
+
+func TestParseIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, contents string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	common := writeFile("common.env", "REGION=us-west-2\nLOG_LEVEL=info\n")
+	name := writeFile("app.env", "# include common.env\n# include your own API key here\nLOG_LEVEL=debug\n")
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := dotconfig.Parse(f, dotconfig.FollowIncludes)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []dotconfig.Entry{
+		{Key: "REGION", Value: "us-west-2", Raw: "us-west-2", Line: 1, File: common},
+		{Key: "LOG_LEVEL", Value: "info", Raw: "info", Line: 2, File: common},
+		{Key: "LOG_LEVEL", Value: "debug", Raw: "debug", Line: 3},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, entries)
+	}
+
+	// Includes that loop back on themselves are an error.
+	writeFile("a.env", "source b.env\n")
+	writeFile("b.env", "# include a.env\n")
+	f, err = os.Open(filepath.Join(dir, "a.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := dotconfig.Parse(f, dotconfig.FollowIncludes); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected include cycle error. Got %v.", err)
+	}
+}
+
+func TestComposeEnvFile(t *testing.T) {
+	type composeConfig struct {
+		Quoted  string `env:"COMPOSE_QUOTED"`
+		Escaped string `env:"COMPOSE_ESCAPED"`
+		Comment string `env:"COMPOSE_COMMENT"`
+		Var     string `env:"COMPOSE_VAR"`
+	}
+	r := strings.NewReader(`# Comments are still comments
+COMPOSE_QUOTED="quoted"
+COMPOSE_ESCAPED=a\nb
+COMPOSE_COMMENT=value # not a comment
+COMPOSE_VAR=${HOME}`)
+	config, err := dotconfig.FromReader[composeConfig](r, dotconfig.ComposeEnvFile)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := composeConfig{
+		Quoted:  `"quoted"`,
+		Escaped: `a\nb`,
+		Comment: "value # not a comment",
+		Var:     "${HOME}",
+	}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+const profileEnv = `PROFILE_LOG_LEVEL=info
+PROFILE_NAME=app
+
+[dev]
+PROFILE_DATABASE_URL=postgres://localhost:5432/app
+PROFILE_LOG_LEVEL=debug
+
+[prod]
+PROFILE_DATABASE_URL=postgres://db.internal:5432/app
+
+[default]
+PROFILE_REGION=us-west-2
+`
+
+func TestProfile(t *testing.T) {
+	type profileConfig struct {
+		LogLevel    string `env:"PROFILE_LOG_LEVEL"`
+		Name        string `env:"PROFILE_NAME"`
+		DatabaseURL string `env:"PROFILE_DATABASE_URL,optional"`
+		Region      string `env:"PROFILE_REGION"`
+	}
+	cases := []struct {
+		profile  string
+		expected profileConfig
+	}{
+		{"", profileConfig{"info", "app", "", "us-west-2"}},
+		{"dev", profileConfig{"debug", "app", "postgres://localhost:5432/app", "us-west-2"}},
+		{"prod", profileConfig{"info", "app", "postgres://db.internal:5432/app", "us-west-2"}},
+	}
+	for _, c := range cases {
+		t.Run(c.profile, func(t *testing.T) {
+			for _, key := range []string{"PROFILE_LOG_LEVEL", "PROFILE_NAME", "PROFILE_DATABASE_URL", "PROFILE_REGION"} {
+				t.Setenv(key, "")
+				os.Unsetenv(key)
+			}
+			config, err := dotconfig.FromReader[profileConfig](strings.NewReader(profileEnv), dotconfig.Profile(c.profile))
+			if err != nil {
+				t.Fatalf("Didn't expect error. Got %v.", err)
+			}
+			if config != c.expected {
+				t.Errorf("Expected:\n%#v\nGot:\n%#v", c.expected, config)
+			}
+		})
+	}
+	// Parse keeps every entry and records its profile.
+	entries, err := dotconfig.Parse(strings.NewReader(profileEnv))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	profiles := []string{}
+	for _, entry := range entries {
+		profiles = append(profiles, entry.Profile)
+	}
+	expected := []string{"", "", "dev", "dev", "prod", ""}
+	if !reflect.DeepEqual(profiles, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, profiles)
+	}
+}
+
+func TestMaxLineLength(t *testing.T) {
+	type longConfig struct {
+		Cert string `env:"LONG_LINE_CERT"`
+		Next string `env:"LONG_LINE_NEXT"`
+	}
+	cert := strings.Repeat("A", 100*1024)
+	env := "LONG_LINE_CERT=" + cert + "\nLONG_LINE_NEXT=next"
+	_, err := dotconfig.FromReader[longConfig](strings.NewReader(env))
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("Expected bufio.ErrTooLong. Got %v.", err)
+	}
+	config, err := dotconfig.FromReader[longConfig](strings.NewReader(env), dotconfig.MaxLineLength(1<<20))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Cert != cert || config.Next != "next" {
+		t.Errorf("Expected long value and next. Got %v bytes and %q.", len(config.Cert), config.Next)
+	}
+
+	// A negative limit means there isn't one.
+	config, err = dotconfig.FromReader[longConfig](strings.NewReader(env), dotconfig.MaxLineLength(-1))
+	if err != nil || config.Cert != cert {
+		t.Errorf("Expected long value. Got %v bytes and %v.", len(config.Cert), err)
+	}
+
+	// Comments are skipped without being read, so the limit doesn't
+	// apply to them.
+	env = "# " + strings.Repeat("x", 1<<20) + "\n;" + strings.Repeat("y", 1<<20) + "\r\nLONG_LINE_CERT=a\nLONG_LINE_NEXT=b"
+	config, err = dotconfig.FromReader[longConfig](strings.NewReader(env), dotconfig.MaxLineLength(64), dotconfig.CommentPrefixes(";"))
+	if err != nil || config.Cert != "a" || config.Next != "b" {
+		t.Errorf("Expected a and b. Got %q, %q and %v.", config.Cert, config.Next, err)
+	}
+	_, err = dotconfig.FromReader[longConfig](strings.NewReader(env), dotconfig.CommentPrefixes(";"), dotconfig.StrictEncoding)
+	var fieldErr *dotconfig.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 2 || fieldErr.Offset != 1<<20+3 {
+		t.Errorf("Expected error on line 2 at byte %v. Got %v.", 1<<20+3, err)
+	}
+}
+
+func TestMultilineValues(t *testing.T) {
+	env := "CERT=\"-----BEGIN CERTIFICATE-----  \r\n" +
+		"# not a comment\n" +
+		"\n" +
+		"MIIB #x\\n-----END CERTIFICATE-----\" # comment\n" +
+		"SINGLE='one\n" +
+		"  two'\n" +
+		"NEXT=after\n"
+	entries, err := dotconfig.Parse(strings.NewReader(env))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []dotconfig.Entry{
+		{Key: "CERT", Value: "-----BEGIN CERTIFICATE-----  \n# not a comment\n\nMIIB #x\n-----END CERTIFICATE-----", Raw: "\"-----BEGIN CERTIFICATE-----  \n# not a comment\n\nMIIB #x\\n-----END CERTIFICATE-----\" # comment", Line: 1},
+		{Key: "SINGLE", Value: "one\n  two", Raw: "'one\n  two'", Line: 5},
+		{Key: "NEXT", Value: "after", Raw: "after", Line: 7},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("Expected:\n%#v\nGot:\n%#v", expected, entries)
+	}
+
+	// A quote that's never closed is an error at the line it opened on.
+	_, err = dotconfig.Parse(strings.NewReader("A=1\nCERT=\"abc\nB=2\n"))
+	var parseErr *dotconfig.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 2 || parseErr.Offset != 4 || !errors.Is(err, dotconfig.ErrMalformedLine) {
+		t.Errorf("Expected malformed line error on line 2 at byte 4. Got %v.", err)
+	}
+	// The whole value counts as one line for MaxLineLength.
+	_, err = dotconfig.Parse(strings.NewReader("CERT=\"0123456789\n0123456789\n0123456789\""), dotconfig.MaxLineLength(25))
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("Expected bufio.ErrTooLong. Got %v.", err)
+	}
+	// Only a comment can follow the closing quote.
+	type multilineConfig struct {
+		Cert string `env:"MULTILINE_CERT"`
+	}
+	_, err = dotconfig.FromReader[multilineConfig](strings.NewReader("MULTILINE_CERT='a\nb' c\n"), dotconfig.StrictSyntax, dotconfig.WithEnviron(map[string]string{}))
+	var fieldErr *dotconfig.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 2 || !errors.Is(err, dotconfig.ErrMalformedLine) {
+		t.Errorf("Expected malformed line error on line 2. Got %v.", err)
+	}
+}
+
+func TestParseErrorOffsets(t *testing.T) {
+	type offsetConfig struct {
+		Key string `env:"OFFSET_KEY"`
+	}
+	r := strings.NewReader("# comment\nOFFSET_KEY=value\noops\n")
+	_, err := dotconfig.FromReader[offsetConfig](r, dotconfig.StrictSyntax)
+	var fieldErr *dotconfig.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 3 || fieldErr.Offset != 27 {
+		t.Fatalf("Expected error on line 3 at byte 27. Got %#v.", fieldErr)
+	}
+
+	env := "OFFSET_KEY=value\r\nOFFSET_KEY=" + strings.Repeat("A", 100) + "\n"
+	_, err = dotconfig.FromReader[offsetConfig](strings.NewReader(env), dotconfig.MaxLineLength(50))
+	if !errors.Is(err, bufio.ErrTooLong) || !strings.Contains(err.Error(), "line 2 (byte 18)") {
+		t.Errorf("Expected error on line 2 at byte 18. Got %v.", err)
+	}
+	_, err = dotconfig.FromReader[offsetConfig](strings.NewReader(env), dotconfig.StrictEncoding)
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 1 || fieldErr.Offset != 0 {
+		t.Errorf("Expected error on line 1 at byte 0. Got %v.", err)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	dir := t.TempDir()
+	inc := filepath.Join(dir, "inc.env")
+	if err := os.WriteFile(inc, []byte("A=1\nB="+strings.Repeat("x", 200)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := "START=1\n# include " + inc + "\n"
+	entries, err := dotconfig.Parse(strings.NewReader(env), dotconfig.FollowIncludes, dotconfig.MaxLineLength(150))
+	var parseErr *dotconfig.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 2 || parseErr.Offset != 8 || parseErr.File != "" {
+		t.Fatalf("Expected error on line 2 at byte 8. Got %v.", err)
+	}
+	if !errors.As(parseErr.Err, &parseErr) || parseErr.Line != 2 || parseErr.Offset != 4 || parseErr.File != inc {
+		t.Fatalf("Expected error on line 2 of %v at byte 4. Got %v.", inc, err)
+	}
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("Expected bufio.ErrTooLong. Got %v.", err)
+	}
+	if len(entries) != 1 || entries[0].Key != "START" {
+		t.Errorf("Expected entries before the error. Got %v.", entries)
+	}
+
+	// Without FollowIncludes, or with NoIncludes, include directives
+	// are just comments.
+	for _, opts := range [][]dotconfig.DecodeOption{
+		{dotconfig.MaxLineLength(150)},
+		{dotconfig.FollowIncludes, dotconfig.NoIncludes, dotconfig.MaxLineLength(150)},
+	} {
+		entries, err = dotconfig.Parse(strings.NewReader(env), opts...)
+		if err != nil {
+			t.Fatalf("Didn't expect error. Got %v.", err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("Expected only START. Got %v.", entries)
+		}
+	}
+
+	// Only bare file names are includes.
+	entries, err = dotconfig.Parse(strings.NewReader("source X=1\n# include $HOME/.env\n"), dotconfig.FollowIncludes)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if len(entries) != 1 || entries[0].Key != "source X" {
+		t.Errorf("Expected source X entry. Got %v.", entries)
+	}
+
+	// Errors that aren't about a line are ParseErrors too.
+	_, err = dotconfig.Parse(bytes.NewReader([]byte{0x1f, 0x8b, 0x08, 0x00}))
+	if !errors.As(err, &parseErr) || parseErr.Line != 0 {
+		t.Errorf("Expected ParseError with no line. Got %#v.", err)
+	}
+
+	// Variables can't be used to build huge values.
+	var b strings.Builder
+	b.WriteString("A0=xx\n")
+	for i := 1; i < 64; i++ {
+		fmt.Fprintf(&b, "A%v=$A%v$A%v\n", i, i-1, i-1)
+	}
+	_, err = dotconfig.Parse(strings.NewReader(b.String()), dotconfig.DotenvSpec)
+	if !errors.As(err, &parseErr) || parseErr.Line != 17 || !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("Expected bufio.ErrTooLong on line 17. Got %v.", err)
+	}
+
+	// The process environment isn't visible to files being parsed.
+	t.Setenv("PARSE_ERROR_SECRET", "hunter2")
+	entries, err = dotconfig.Parse(strings.NewReader("LEAK=${PARSE_ERROR_SECRET}\n"), dotconfig.DotenvSpec)
+	if err != nil || len(entries) != 1 || entries[0].Value != "" {
+		t.Errorf("Expected empty LEAK. Got %v and %v.", entries, err)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"KEY=value\n",
+		"KEY='single # quoted'\nKEY2=\"double\\nquoted\"\r\n",
+		"\ufeff# dotconfig: required, type=int, desc=A, b\nN=1 # comment\n",
+		"[dev]\nKEY+=more\n[default]\n=\n'\n\"\n",
+		"export KEY: `tick`\nMULTI=\"a\nb\"\nX=${KEY:-d}$KEY\\$\n",
+		"# include other.env\nsource other.env\n",
+		"CERT=\"a\r\n# b\n\nc\" # d\nOPEN='x\n;y\n",
+	} {
+		f.Add([]byte(seed))
+	}
+	opts := [][]dotconfig.DecodeOption{
+		{dotconfig.MaxLineLength(64)},
+		{dotconfig.ComposeEnvFile},
+		{dotconfig.DotenvSpec, dotconfig.CommentPrefixes(";", "")},
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lines := bytes.Count(data, []byte("\n")) + 1
+		if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+			// Gzipped input has its own lines.
+			lines = math.MaxInt
+		}
+		for _, opts := range opts {
+			entries, err := dotconfig.Parse(bytes.NewReader(data), opts...)
+			var parseErr *dotconfig.ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				t.Fatalf("Expected ParseError. Got %T: %v.", err, err)
+			}
+			// Parse turns panics into errors rather than crash, but
+			// they're still bugs.
+			if err != nil && strings.Contains(err.Error(), "internal error:") {
+				t.Fatalf("Didn't expect a panic. Got %v.", err)
+			}
+			for _, e := range entries {
+				if e.Line < 1 || e.Line > lines || e.File != "" {
+					t.Fatalf("Expected entry within the %v lines of input. Got %#v.", lines, e)
+				}
+			}
+		}
+	})
+}
+
+func TestStrictSyntax(t *testing.T) {
+	type strictConfig struct {
+		Key string `env:"STRICT_SYNTAX_KEY"`
+	}
+	r := strings.NewReader("# Comments are fine\n\nSTRICT_SYNTAX_KEY value\nSTRICT_SYNTAX_KEY=value\noops")
+	_, err := dotconfig.FromReader[strictConfig](r, dotconfig.StrictSyntax)
+	expected := []string{
+		"malformed line: line 3: expected KEY=VALUE",
+		"malformed line: line 5: expected KEY=VALUE",
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expected), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] || !errors.Is(err, dotconfig.ErrMalformedLine) {
+			t.Errorf("Expected %q. Got %q.", expected[i], err)
+		}
+	}
+}
+
+func TestCommentPrefixes(t *testing.T) {
+	type commentConfig struct {
+		Key string `env:"COMMENT_PREFIX_KEY"`
+	}
+	r := strings.NewReader("; INI style\n// C style\n# Still a comment\nCOMMENT_PREFIX_KEY=value")
+	config, res, err := dotconfig.LoadWithResult[commentConfig](r, dotconfig.CommentPrefixes(";", "//"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	if config.Key != "value" {
+		t.Errorf("Expected value. Got %q.", config.Key)
+	}
+	if len(res.Warnings) != 0 {
+		t.Errorf("Expected no warnings. Got %v.", res.Warnings)
+	}
+}
+
+func TestWindowsEncoding(t *testing.T) {
+	type windowsConfig struct {
+		First  string `env:"WINDOWS_FIRST"`
+		Second string `env:"WINDOWS_SECOND"`
+	}
+	env := "\ufeffWINDOWS_FIRST=first\r\nWINDOWS_SECOND='second'\r\n"
+	for _, opts := range [][]dotconfig.DecodeOption{{}, {dotconfig.DotenvSpec}} {
+		config, err := dotconfig.FromReader[windowsConfig](strings.NewReader(env), opts...)
+		if err != nil {
+			t.Fatalf("Didn't expect error. Got %v.", err)
+		}
+		expected := windowsConfig{First: "first", Second: "second"}
+		if config != expected {
+			t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+		}
+		strict := append(opts, dotconfig.StrictEncoding)
+		_, err = dotconfig.FromReader[windowsConfig](strings.NewReader(env), strict...)
+		if !errors.Is(err, dotconfig.ErrMalformedLine) || err.Error() != "malformed line: line 1: file starts with a byte order mark" {
+			t.Errorf("Expected byte order mark error. Got %v.", err)
+		}
+		_, err = dotconfig.FromReader[windowsConfig](strings.NewReader(env[len("\ufeff"):]), strict...)
+		if !errors.Is(err, dotconfig.ErrMalformedLine) || err.Error() != `malformed line: line 1: Windows line ending (\r\n)` {
+			t.Errorf("Expected line ending error. Got %v.", err)
+		}
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	type keyConfig struct {
+		Name  string `env:"app_name,optional"`
+		Debug string `env:"_9LIVES,optional"`
+	}
+	env := "app.name=app\n9LIVES=cat"
+
+	_, res, err := dotconfig.LoadWithResult[keyConfig](strings.NewReader(env))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	res.Unset()
+	if len(res.Warnings) < 2 || res.Warnings[0].Kind != dotconfig.WarnInvalidKey || res.Warnings[1].Kind != dotconfig.WarnInvalidKey {
+		t.Errorf("Expected invalid key warnings. Got %v.", res.Warnings)
+	}
+
+	config, res, err := dotconfig.LoadWithResult[keyConfig](strings.NewReader(env), dotconfig.SanitizeKeys)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	res.Unset()
+	expected := keyConfig{Name: "app", Debug: "cat"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	_, err = dotconfig.FromReader[keyConfig](strings.NewReader(env), dotconfig.RejectInvalidKeys)
+	expectedErrs := []string{
+		"malformed line: app.name: keys must match [A-Za-z_][A-Za-z0-9_]*",
+		"malformed line: 9LIVES: keys must match [A-Za-z_][A-Za-z0-9_]*",
+	}
+	errs := dotconfig.Errors(err)
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("Expected %v errors. Got %v.", len(expectedErrs), err)
+	}
+	for i, err := range errs {
+		if err.Error() != expectedErrs[i] {
+			t.Errorf("Expected %q. Got %q.", expectedErrs[i], err)
+		}
+	}
+	if _, ok := os.LookupEnv("app.name"); ok {
+		t.Error("Expected nothing to be set when keys are rejected.")
+	}
+}
+
+func TestParseRaw(t *testing.T) {
+	r := strings.NewReader("QUOTED='a b' # note\nESCAPED=\"line1\\nline2\"\nSPACED=  value  ")
+	entries, err := dotconfig.Parse(r)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []string{"'a b' # note", `"line1\nline2"`, "  value  "}
+	raw := []string{}
+	for _, entry := range entries {
+		raw = append(raw, entry.Raw)
+	}
+	if !reflect.DeepEqual(raw, expected) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, raw)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	type gzipConfig struct {
+		Region string `env:"GZIP_REGION"`
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("GZIP_REGION=us-west-2\n"))
+	zw.Close()
+	name := filepath.Join(t.TempDir(), ".env.gz")
+	if err := os.WriteFile(name, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := dotconfig.FromFileName[gzipConfig](name)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Region != "us-west-2" {
+		t.Errorf("Expected us-west-2. Got %q.", config.Region)
+	}
+}
+
+func TestGzipLimit(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("KEY=" + strings.Repeat("a", 2000) + "\n"))
+	zw.Close()
+	_, err := dotconfig.Parse(bytes.NewReader(buf.Bytes()), dotconfig.MaxDecompressedSize(1024))
+	var parseErr *dotconfig.ParseError
+	if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "more than 1024 bytes") {
+		t.Errorf("Expected ParseError for more than 1024 bytes. Got %v.", err)
+	}
+	// Files and their includes are limited too.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "big.env.gz"), buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("# include big.env.gz\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"big.env.gz", "app.env"} {
+		_, err := dotconfig.FromFileName[struct{}](filepath.Join(dir, name), dotconfig.MaxDecompressedSize(1024), dotconfig.WithEnviron(map[string]string{}))
+		if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "more than 1024 bytes") {
+			t.Errorf("%v: expected ParseError for more than 1024 bytes. Got %v.", name, err)
+		}
+	}
+
+	// Exactly the limit is fine.
+	entries, err := dotconfig.Parse(bytes.NewReader(buf.Bytes()), dotconfig.MaxDecompressedSize(2005))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected 1 entry. Got %v and %v.", entries, err)
+	}
+	if _, err := dotconfig.Parse(bytes.NewReader(buf.Bytes()), dotconfig.MaxDecompressedSize(-1)); err != nil {
+		t.Errorf("Didn't expect error. Got %v.", err)
+	}
+}
+
+// TestDotenvSpec checks the shared corpus in testdata/dotenv-spec.
+func TestDotenvSpec(t *testing.T) {
+	b, err := os.ReadFile("testdata/dotenv-spec/expected.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected map[string]string
+	if err := json.Unmarshal(b, &expected); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open("testdata/dotenv-spec/cases.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	res, err := dotconfig.Load(f, dotconfig.DotenvSpec)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	t.Cleanup(res.Unset)
+	if len(res.Warnings) != 0 {
+		t.Errorf("Didn't expect warnings. Got %v.", res.Warnings)
+	}
+	if len(res.KeysSet) != len(expected) {
+		t.Errorf("Expected %v keys. Got %v: %v", len(expected), len(res.KeysSet), res.KeysSet)
+	}
+	for key, value := range expected {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("%v: expected %q. Got %q.", key, value, got)
+		}
+	}
+}
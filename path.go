@@ -0,0 +1,69 @@
+package dotconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// decodePath expands and cleans value for fields tagged path. A leading
+// ~ and $HOME or ${HOME} anywhere become the user's home directory.
+// Other variables are left alone, use the expand tag option for those.
+// With mustexist, the path has to exist too.
+func (o options) decodePath(value string, tagOpts tagOptions) (string, error) {
+	if value == "~" || strings.HasPrefix(value, "~/") || strings.Contains(value, "$HOME") || strings.Contains(value, "${HOME}") {
+		home, err := o.homeDir()
+		if err != nil {
+			return "", err
+		}
+		if rest, ok := strings.CutPrefix(value, "~"); ok {
+			value = home + rest
+		}
+		value = replaceHome(value, home)
+	}
+	value = filepath.Clean(value)
+	if tagOpts.Contains("mustexist") {
+		if _, err := os.Stat(value); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// homeDir returns HOME from the environment being decoded, so it honors
+// [WithEnviron] and [NoExport] like any other variable. Only without
+// WithEnviron do we fall back to [os.UserHomeDir], which knows where
+// home is on systems that don't set HOME.
+func (o options) homeDir() (string, error) {
+	if home, ok := o.getenv("HOME"); ok && home != "" {
+		return home, nil
+	}
+	if o.Environ == nil {
+		return os.UserHomeDir()
+	}
+	return "", errors.New("$HOME is not defined")
+}
+
+// replaceHome replaces ${HOME}, and $HOME when it isn't the start of a
+// longer name like $HOMEPATH, with home.
+func replaceHome(value, home string) string {
+	value = strings.ReplaceAll(value, "${HOME}", home)
+	var b strings.Builder
+	for {
+		i := strings.Index(value, "$HOME")
+		if i < 0 {
+			break
+		}
+		end := i + len("$HOME")
+		b.WriteString(value[:i])
+		if end < len(value) && isSpecNameByte(value[end]) {
+			b.WriteString("$HOME")
+		} else {
+			b.WriteString(home)
+		}
+		value = value[end:]
+	}
+	b.WriteString(value)
+	return b.String()
+}
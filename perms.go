@@ -0,0 +1,32 @@
+package dotconfig
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"runtime"
+)
+
+// checkPermissions looks at the mode of r, if it's a file, for the
+// [CheckPermissions] and [StrictPermissions] options. Env files often
+// hold secrets, so like ssh with private keys we'd rather complain
+// about a file anyone on the machine can read. Permission bits don't
+// mean the same thing on Windows, so it's skipped there.
+func (o options) checkPermissions(r io.Reader, name string) ([]Warning, error) {
+	if !o.CheckPermissions && !o.StrictPermissions || runtime.GOOS == "windows" {
+		return nil, nil
+	}
+	f, ok := r.(interface{ Stat() (fs.FileInfo, error) })
+	if !ok {
+		return nil, nil
+	}
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o077 == 0 {
+		return nil, nil
+	}
+	if o.StrictPermissions {
+		return nil, fmt.Errorf("%w: %v has mode %v", ErrLoosePermissions, name, info.Mode().Perm())
+	}
+	msg := fmt.Sprintf("%v has mode %v, so it can be read by other users", name, info.Mode().Perm())
+	return []Warning{{Kind: WarnLoosePermissions, Message: msg}}, nil
+}
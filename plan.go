@@ -0,0 +1,139 @@
+package dotconfig
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// typePlan is everything decodeEnv needs to know about a config type's
+// fields that only depends on the type, worked out once and cached so
+// reloads skip parsing and checking tags, and decode each value without
+// working out how again.
+type typePlan struct {
+	// fields has the exported fields, which are the ones decodeEnv can
+	// set.
+	fields []fieldPlan
+	// hasGroups is true if any field has a group tag.
+	hasGroups bool
+	// hasRest is true if any field is tagged `env:",rest"`.
+	hasRest bool
+}
+
+// fieldPlan holds a field's parsed tags.
+type fieldPlan struct {
+	// index is the field's index in the struct.
+	index   int
+	field   reflect.StructField
+	envKey  string
+	tagOpts tagOptions
+	// decode decodes the field's values. See compileDecoder.
+	decode decoder
+	// The tag options decodeEnv checks for every value.
+	optional, required, present, nonzero bool
+	export, expand, noexpand             bool
+	trim, notrim, lower, upper           bool
+	// defaultValue is the value of the default tag, if hasDefault.
+	defaultValue string
+	hasDefault   bool
+	// group is the group tag, if grouped.
+	group   string
+	grouped bool
+	// implicitlyOptional, optionalType, lazy and featureFlags are
+	// about the field's type.
+	implicitlyOptional, optionalType, lazy, featureFlags bool
+	// tagErr is the problem checkTag found with the tags, if any.
+	tagErr error
+	// runtimeName and runtime are set for fields like
+	// `env:",hostname"`. See runtimeValue.
+	runtimeName string
+	runtime     func() (string, error)
+	desc        string
+	deprecated  []string
+	source      string
+	sep         string
+	// from is the key from the from tag option, see [WithResolver].
+	from string
+}
+
+// planKey identifies a cached plan. Besides the type, it has the
+// options that change how tags are read.
+type planKey struct {
+	t               reflect.Type
+	caarlos0        bool
+	envconfig       bool
+	envconfigPrefix string
+}
+
+var plans sync.Map // planKey -> *typePlan
+
+// plan returns the plan for the struct type ct, building it the first
+// time.
+func (o options) plan(ct reflect.Type) *typePlan {
+	key := planKey{t: ct, caarlos0: o.Caarlos0Compat, envconfig: o.EnvconfigCompat, envconfigPrefix: o.EnvconfigPrefix}
+	if p, ok := plans.Load(key); ok {
+		return p.(*typePlan)
+	}
+	p := &typePlan{}
+	for i := range ct.NumField() {
+		field := ct.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		envKey, tagOpts := o.fieldTag(field)
+		fp := fieldPlan{
+			index:        i,
+			field:        field,
+			envKey:       envKey,
+			tagOpts:      tagOpts,
+			optional:     tagOpts.Contains("optional"),
+			required:     tagOpts.Contains("required"),
+			present:      tagOpts.Contains("present"),
+			nonzero:      tagOpts.Contains("nonzero"),
+			export:       tagOpts.Contains("export"),
+			expand:       tagOpts.Contains("expand"),
+			noexpand:     tagOpts.Contains("noexpand"),
+			trim:         tagOpts.Contains("trim"),
+			notrim:       tagOpts.Contains("notrim"),
+			lower:        tagOpts.Contains("lower"),
+			upper:        tagOpts.Contains("upper"),
+			desc:         field.Tag.Get("desc"),
+			source:       field.Tag.Get("source"),
+			sep:          o.separator(field),
+			deprecated:   deprecatedKeys(field),
+			optionalType: isOptionalType(field.Type),
+			lazy:         isLazyType(field.Type),
+			featureFlags: field.Type == featureFlagsType,
+		}
+		fp.implicitlyOptional = implicitlyOptional(field.Type)
+		fp.defaultValue, fp.hasDefault = o.fieldDefault(field)
+		fp.group, fp.grouped = field.Tag.Lookup("group")
+		fp.group = strings.TrimSpace(fp.group)
+		fp.decode = compileDecoder(field.Type, tagOpts, fp.sep)
+		fp.from, _ = tagOpts.Value("from")
+		if envKey != "" {
+			fp.tagErr = checkTag(field, tagOpts)
+		}
+		fp.runtimeName, fp.runtime, _ = runtimeValue(tagOpts)
+		if fp.grouped {
+			p.hasGroups = true
+		}
+		if isRestField(envKey, tagOpts) {
+			p.hasRest = true
+		}
+		p.fields = append(p.fields, fp)
+	}
+	actual, _ := plans.LoadOrStore(key, p)
+	return actual.(*typePlan)
+}
+
+// deprecatedKeys returns the old names listed in field's deprecated tag.
+func deprecatedKeys(field reflect.StructField) []string {
+	var keys []string
+	for _, key := range strings.Split(field.Tag.Get("deprecated"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,43 @@
+package dotconfig
+
+// Profile selects the active section of a sectioned env file, so every
+// environment can live in one reviewed file:
+//
+//	LOG_LEVEL=info
+//
+//	[dev]
+//	DATABASE_URL=postgres://localhost:5432/app
+//	LOG_LEVEL=debug
+//
+//	[prod]
+//	DATABASE_URL=postgres://db.internal:5432/app
+//
+// Entries before the first section (or in a [default] section) always
+// apply, and entries in the active profile override them. Other
+// sections are ignored, as are all sections when there's no Profile
+// option.
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.Profile(os.Getenv("APP_ENV")))
+func Profile(name string) DecodeOption {
+	return funcOption(func(o *options) {
+		if name == defaultProfile {
+			name = ""
+		}
+		o.Profile = name
+	})
+}
+
+// profileEntries returns the entries that apply to the active profile,
+// with default entries first so the profile's values win.
+func (o options) profileEntries(entries []Entry) []Entry {
+	var defaults, active []Entry
+	for _, entry := range entries {
+		switch {
+		case entry.Profile == "":
+			defaults = append(defaults, entry)
+		case entry.Profile == o.Profile:
+			active = append(active, entry)
+		}
+	}
+	return append(defaults, active...)
+}
@@ -0,0 +1,51 @@
+package dotconfig
+
+import "reflect"
+
+// Refresh decodes the environment into the already populated struct
+// that ptr points to, for services that reload config without
+// restarting. Fields tagged frozen keep the value they had, for settings
+// like a listen address that can't change once the service is running:
+//
+//	type AppConfig struct {
+//		Addr     string `env:"ADDR,frozen"`
+//		LogLevel string `env:"LOG_LEVEL"`
+//	}
+//
+// Frozen fields are still decoded, so a bad value is still an error, and
+// if the value changed there's a [WarnFrozenField] warning (see
+// [OnWarning]) saying a restart is needed to apply it. If there are
+// errors, the struct isn't changed unless the [AllowPartial] option is
+// set.
+func Refresh(ptr any, opts ...DecodeOption) error {
+	config, err := structElem(ptr)
+	if err != nil {
+		return err
+	}
+	ops := optsFromVariadic(opts)
+	cv := reflect.New(config.Type()).Elem()
+	cv.Set(config)
+	err = decodeEnv(cv, ops, &Result{})
+	if err != nil && !ops.AllowPartial {
+		return err
+	}
+	res := Result{}
+	ct := cv.Type()
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		if !cv.Field(i).CanSet() {
+			continue
+		}
+		envKey, tagOpts := ops.fieldTag(field)
+		if envKey == "" || !tagOpts.Contains("frozen") {
+			continue
+		}
+		if !reflect.DeepEqual(cv.Field(i).Interface(), config.Field(i).Interface()) {
+			res.warn(WarnFrozenField, envKey, 0, "%v changed but %v is frozen, restart to apply it", envKey, field.Name)
+		}
+		cv.Field(i).Set(config.Field(i))
+	}
+	ops.reportWarnings(&res)
+	config.Set(cv)
+	return err
+}
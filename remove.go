@@ -0,0 +1,60 @@
+package dotconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// shred overwrites the file called name with zeros, truncates it and
+// removes it, for the [RemoveAfterLoad] option. Journaling and copy on
+// write filesystems (and SSDs) may keep old blocks around anyway, so
+// this is best effort, but it beats leaving secrets sitting on disk.
+func shred(name string) error {
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("removing env file: %w", err)
+	}
+	info, err := f.Stat()
+	if err == nil {
+		_, err = io.CopyN(f, zeros{}, info.Size())
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = f.Truncate(0)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Remove(name)
+	}
+	if err != nil {
+		return fmt.Errorf("removing env file: %w", err)
+	}
+	return nil
+}
+
+// zeros is an [io.Reader] that never runs out of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// removeAfterLoad shreds the files that a successful load read from,
+// if the [RemoveAfterLoad] option is set.
+func (o options) removeAfterLoad(names ...string) error {
+	if !o.RemoveAfterLoad {
+		return nil
+	}
+	for _, name := range names {
+		if err := shred(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
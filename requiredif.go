@@ -0,0 +1,39 @@
+package dotconfig
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// requiredIf checks a field's requiredif tag, like
+// `requiredif:"LOG_EMAIL=false"`. The field is required when the other
+// key is set to that value (ignoring case, so TRUE matches true) and
+// optional otherwise. It returns the condition for error messages, and
+// ok is false for fields without the tag.
+func (o options) requiredIf(field reflect.StructField) (required bool, cond string, ok bool) {
+	cond, ok = field.Tag.Lookup("requiredif")
+	if !ok {
+		return false, "", false
+	}
+	key, want, _ := strings.Cut(cond, "=")
+	value, exists := o.lookupEnv(strings.TrimSpace(key))
+	return exists && strings.EqualFold(strings.TrimSpace(value), strings.TrimSpace(want)), cond, true
+}
+
+// checkRequiredIf looks for problems with a field's requiredif tag.
+func checkRequiredIf(field reflect.StructField, tagOpts tagOptions) error {
+	cond, ok := field.Tag.Lookup("requiredif")
+	if !ok {
+		return nil
+	}
+	if key, _, found := strings.Cut(cond, "="); !found || strings.TrimSpace(key) == "" {
+		return errors.New(`requiredif must look like "KEY=value"`)
+	}
+	for _, name := range []string{"required", "present", "optional"} {
+		if tagOpts.Contains(name) {
+			return errors.New("requiredif fields can't also be " + name)
+		}
+	}
+	return nil
+}
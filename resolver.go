@@ -0,0 +1,109 @@
+package dotconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Resolver looks up the value that a reference like
+// vault://secret/db#password points to. Fields tagged with the from
+// option read a reference from another key and resolve it with the
+// Resolver registered for its scheme. See [WithResolver].
+type Resolver interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as a
+// [Resolver].
+type ResolverFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+// Resolve calls f(ctx, ref).
+func (f ResolverFunc) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	return f(ctx, ref)
+}
+
+// WithResolver registers r for references with the given scheme. A
+// field tagged `env:"DB_PASSWORD,from=DB_PASSWORD_REF"` is set from
+// DB_PASSWORD as usual, but if DB_PASSWORD_REF is set instead, its value
+// is a reference that's resolved to get the password:
+//
+//	DB_PASSWORD_REF=vault://secret/db#password
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.WithResolver("vault", vault))
+//
+// References with the file scheme, like file:///run/secrets/db, read the
+// file without registering anything, with a trailing newline stripped.
+// Setting both keys is an error, since it's not clear which was meant.
+// The [LoadTimeout] option limits how long each reference takes to
+// resolve.
+func WithResolver(scheme string, r Resolver) DecodeOption {
+	return funcOption(func(o *options) {
+		if o.Resolvers == nil {
+			o.Resolvers = map[string]Resolver{}
+		}
+		o.Resolvers[strings.ToLower(scheme)] = r
+	})
+}
+
+// resolveFrom looks up refKey, the key from a field's from option, and
+// resolves the reference in it. It reports false if refKey isn't set.
+func (o options) resolveFrom(refKey string) (string, bool, error) {
+	ref, ok := o.lookupEnv(refKey)
+	if !ok || strings.TrimSpace(ref) == "" {
+		return "", false, nil
+	}
+	value, err := o.resolve(strings.TrimSpace(ref))
+	if err != nil {
+		return "", true, fmt.Errorf("resolving %v: %w", refKey, err)
+	}
+	return value, true, nil
+}
+
+// resolve returns the value ref points to, using the [Resolver] for its
+// scheme.
+func (o options) resolve(ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" {
+		return "", fmt.Errorf("%q isn't a reference like file:///run/secrets/db", ref)
+	}
+	r, ok := o.Resolvers[u.Scheme]
+	if !ok && u.Scheme == "file" {
+		r, ok = fileResolver, true
+	}
+	if !ok {
+		return "", fmt.Errorf("no resolver for scheme %q, see dotconfig.WithResolver", u.Scheme)
+	}
+	ctx := context.Background()
+	if o.LoadTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.LoadTimeout)
+		defer cancel()
+	}
+	return r.Resolve(ctx, u)
+}
+
+// fileResolver reads file references, like a mounted Docker or
+// Kubernetes secret.
+var fileResolver = ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+	if ref.Host != "" && ref.Host != "localhost" {
+		return "", errors.New("file references can't have a host")
+	}
+	// file:relative/path has no slashes after the scheme, so the path
+	// is opaque.
+	path := ref.Path
+	if ref.Opaque != "" {
+		path = ref.Opaque
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(contents), "\n"), nil
+})
@@ -0,0 +1,44 @@
+package dotconfig
+
+import (
+	"errors"
+	"reflect"
+)
+
+var stringMapType = reflect.TypeOf(map[string]string(nil))
+
+// isRestField reports whether a field is tagged `env:",rest"`, which
+// collects every key read during the load that no other field claims.
+// It's for pass-through proxies that forward arbitrary config
+// downstream.
+func isRestField(envKey string, tagOpts tagOptions) bool {
+	return envKey == "" && tagOpts.Contains("rest")
+}
+
+// checkRestField looks for problems with a rest field. seen is true if
+// an earlier field was already tagged rest.
+func checkRestField(field reflect.StructField, seen bool) *FieldError {
+	switch {
+	case seen:
+		return &FieldError{Field: field.Name, Path: field.Name, Err: ErrInvalidTag, Cause: errors.New("only one field can be tagged rest")}
+	case field.Type != stringMapType:
+		return &FieldError{Field: field.Name, Path: field.Name, Err: ErrUnsupportedFieldType, Cause: errors.New("rest fields must be map[string]string")}
+	}
+	return nil
+}
+
+// restValues returns the keys set during the load that aren't in
+// claimed, along with their values, and claims them.
+func (o options) restValues(res *Result, claimed map[string]bool) map[string]string {
+	rest := map[string]string{}
+	for _, key := range res.KeysSet {
+		if claimed[key] {
+			continue
+		}
+		if value, ok := o.getenv(key); ok {
+			rest[key] = value
+		}
+		claimed[key] = true
+	}
+	return rest
+}
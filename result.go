@@ -0,0 +1,213 @@
+package dotconfig
+
+import (
+	"fmt"
+	"os"
+)
+
+// Result contains metadata about a single load. It is returned by
+// [LoadWithResult] and is mostly useful for startup diagnostics.
+type Result struct {
+	// KeysSet are the keys that were read from the reader and set
+	// in the environment, in the order they appeared.
+	KeysSet []string
+	// Defaults are the names of struct fields that were populated
+	// from a `default` struct tag because their key was missing.
+	Defaults []string
+	// Skipped are the names of struct fields marked optional whose
+	// key was missing, so they were left as their zero value.
+	Skipped []string
+	// Warnings are non-fatal problems found while loading, such as
+	// malformed lines that were ignored. See also [OnWarning].
+	Warnings []Warning
+	// Origins maps the names of struct fields to where their values
+	// came from. Pass the Result to [Explain] or [DebugHandler] with
+	// [WithResult] to report them.
+	Origins map[string]Origin
+
+	// changes records each variable set during the load so they
+	// can be reverted by Unset.
+	changes []envChange
+	// lines maps keys to the line they were last set from.
+	lines map[string]int
+	// sources maps keys to the name of the backend that last set them,
+	// for source tags.
+	sources map[string]string
+	// resolved records where each field's value came from, for
+	// [AuditLog].
+	resolved []AuditKey
+	// env is the environment from [WithEnviron] that values are set
+	// in, or nil for the process environment.
+	env map[string]string
+	// discard is set when nothing looks at the Result after the load,
+	// like in Bind, so there's no need to record defaults, skipped
+	// fields and warnings.
+	discard bool
+}
+
+// WarningKind identifies the kind of a [Warning].
+type WarningKind string
+
+const (
+	WarnMalformedLine     WarningKind = "malformed_line"     // A line that isn't blank, a comment, or KEY=VALUE was skipped
+	WarnUnknownDirective  WarningKind = "unknown_directive"  // A schema comment had a directive we don't understand
+	WarnWhitespaceTrimmed WarningKind = "whitespace_trimmed" // Trailing whitespace was trimmed from an unquoted value
+	WarnConflict          WarningKind = "conflict"           // See [ReportConflicts]
+	WarnDeprecatedKey     WarningKind = "deprecated_key"     // A key from a deprecated tag was used
+	WarnDefaultApplied    WarningKind = "default_applied"    // A field was set from its default tag
+	WarnUnknownKey        WarningKind = "unknown_key"        // A key read from a file doesn't match any field
+	WarnInvalidKey        WarningKind = "invalid_key"        // A key read from a file isn't a valid environment variable name
+	WarnLoosePermissions  WarningKind = "loose_permissions"  // See [CheckPermissions]
+	WarnFrozenField       WarningKind = "frozen_field"       // A field tagged frozen has a new value that [Refresh] didn't apply
+)
+
+// Warning is a non-fatal problem found while loading config. Warnings
+// are hygiene issues you probably want to log but that shouldn't stop
+// your app from starting.
+type Warning struct {
+	Kind WarningKind
+	// Key is the env key the warning is about, if any.
+	Key string
+	// Line is the line of the env file the warning is about, or 0.
+	Line int
+	// Offset is the byte offset of the start of Line, for warnings
+	// about malformed lines. It's 0 otherwise.
+	Offset int64
+	// Message describes the problem. It never includes values.
+	Message string
+}
+
+// String formats the warning with its line number, if any.
+func (w Warning) String() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("line %d: %v", w.Line, w.Message)
+	}
+	return w.Message
+}
+
+// OnWarning calls fn for each warning found while loading, after the
+// config has been decoded. This is how to see warnings from functions
+// like [FromFileName] that don't return a [Result]:
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env", dotconfig.OnWarning(func(w dotconfig.Warning) {
+//		log.Println("config warning:", w)
+//	}))
+func OnWarning(fn func(Warning)) DecodeOption {
+	return funcOption(func(o *options) {
+		o.OnWarning = fn
+	})
+}
+
+// discardResult returns a Result for a load whose Result is thrown
+// away. Warnings are still kept for the [OnWarning] callback.
+func (o options) discardResult() *Result {
+	return &Result{discard: o.OnWarning == nil}
+}
+
+// warn records a warning.
+func (r *Result) warn(kind WarningKind, key string, line int, format string, args ...any) {
+	if r.discard {
+		return
+	}
+	r.Warnings = append(r.Warnings, Warning{Kind: kind, Key: key, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// defaulted records that field was set from its default tag because
+// key wasn't set.
+func (r *Result) defaulted(field, key string) {
+	if r.discard {
+		return
+	}
+	r.Defaults = append(r.Defaults, field)
+	r.warn(WarnDefaultApplied, key, 0, "%v not set, using default for %v", key, field)
+}
+
+// skip records that field was left unset.
+func (r *Result) skip(field string) {
+	if !r.discard {
+		r.Skipped = append(r.Skipped, field)
+		r.setOrigin(field, OriginUnset)
+	}
+}
+
+// setOrigin records where field's value came from.
+func (r *Result) setOrigin(field string, origin Origin) {
+	if r.discard {
+		return
+	}
+	if r.Origins == nil {
+		r.Origins = map[string]Origin{}
+	}
+	r.Origins[field] = origin
+}
+
+// envChange is a single os.Setenv call and the value it replaced.
+type envChange struct {
+	key     string
+	prev    string
+	existed bool
+	// process is true for changes to the process environment even
+	// though the Result sets values somewhere else, from the export tag.
+	process bool
+}
+
+// setenv sets key in the environment, or in env if it isn't nil, and
+// records the change. source is
+// the name of the backend the value came from and line is the line of
+// the env file it was on, or 0 if it didn't come from a file.
+func (r *Result) setenv(key, value, source string, line int) {
+	var prev string
+	var existed bool
+	if r.env != nil {
+		prev, existed = r.env[key]
+		r.env[key] = value
+	} else {
+		prev, existed = os.LookupEnv(key)
+		os.Setenv(key, value)
+	}
+	r.KeysSet = append(r.KeysSet, key)
+	r.changes = append(r.changes, envChange{key: key, prev: prev, existed: existed})
+	if r.sources == nil {
+		r.sources = map[string]string{}
+	}
+	r.sources[key] = source
+	if line > 0 {
+		if r.lines == nil {
+			r.lines = map[string]int{}
+		}
+		r.lines[key] = line
+	}
+}
+
+// line returns the line of the env file that key was set from, or 0.
+func (r *Result) line(key string) int {
+	return r.lines[key]
+}
+
+// Unset reverts the environment variables that were set during the
+// load, restoring previous values and unsetting keys that didn't exist
+// before. This lets tests and tools load a file temporarily without
+// permanently polluting the process environment:
+//
+//	conf, res, err := dotconfig.LoadWithResult[myconfig](file)
+//	defer res.Unset()
+func (r Result) Unset() {
+	// Walk backwards so keys set more than once end up with the
+	// value they had before the load.
+	for i := len(r.changes) - 1; i >= 0; i-- {
+		c := r.changes[i]
+		if r.env != nil && !c.process {
+			if c.existed {
+				r.env[c.key] = c.prev
+			} else {
+				delete(r.env, c.key)
+			}
+			continue
+		}
+		if c.existed {
+			os.Setenv(c.key, c.prev)
+		} else {
+			os.Unsetenv(c.key)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package dotconfig
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// maxRetryBackoff caps the delay between attempts for [Retry].
+const maxRetryBackoff = 30 * time.Second
+
+// Retry makes fetches from a [Source] try up to attempts times before
+// giving up, so a secret manager hiccup at startup doesn't turn into a
+// crash-looping pod:
+//
+//	conf, err := dotconfig.FromSource[AppConfig](ctx, vault, dotconfig.Retry(5, 100*time.Millisecond))
+//
+// The delay starts at backoff and doubles after each failure, up to 30
+// seconds, with jitter so a fleet of instances doesn't retry in lock
+// step. Retries stop early when ctx is done.
+func Retry(attempts int, backoff time.Duration) DecodeOption {
+	return funcOption(func(o *options) {
+		o.RetryAttempts = attempts
+		o.RetryBackoff = backoff
+	})
+}
+
+// fetch fetches values from src, retrying failures if the [Retry]
+// option is set. It returns the last error.
+func (o options) fetch(ctx context.Context, src Source) (values map[string]string, err error) {
+	defer func(start time.Time) {
+		o.Metrics.sourceFetched(sourceName(src), time.Since(start), err)
+	}(time.Now())
+	delay := o.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		values, err = fetchWithContext(ctx, src)
+		if err == nil || attempt >= o.RetryAttempts || ctx.Err() != nil {
+			return values, err
+		}
+		timer := time.NewTimer(jitter(delay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return values, err
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxRetryBackoff)
+	}
+}
+
+// jitter returns a random duration between d/2 and d.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d-d/2)
+}
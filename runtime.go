@@ -0,0 +1,32 @@
+package dotconfig
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// runtimeValues are pseudo-sources for fields tagged with an empty key
+// and one of these names, like `env:",hostname"`. They fill in instance
+// identity that every service would otherwise compute for itself.
+var runtimeValues = map[string]func() (string, error){
+	"hostname": os.Hostname,
+	"pid": func() (string, error) {
+		return strconv.Itoa(os.Getpid()), nil
+	},
+	// RFC 3339 is what time.Time unmarshals, and it reads fine in a
+	// string field too.
+	"now": func() (string, error) {
+		return time.Now().Format(time.RFC3339Nano), nil
+	},
+}
+
+// runtimeValue returns the pseudo-source named in tagOpts, if any.
+func runtimeValue(tagOpts tagOptions) (string, func() (string, error), bool) {
+	for _, name := range tagOpts.names() {
+		if fn, ok := runtimeValues[name]; ok {
+			return name, fn, true
+		}
+	}
+	return "", nil, false
+}
@@ -0,0 +1,87 @@
+package dotconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Secret holds a value that redacts itself, so credentials can't end up
+// in logs by accident. It prints as *** with every fmt verb, marshals to
+// "***" in JSON, and is always redacted by [Explain]. Call
+// [Secret.Reveal] to get the value:
+//
+//	type AppConfig struct {
+//		StripeKey dotconfig.Secret[string] `env:"STRIPE_KEY"`
+//	}
+//
+//	stripe.Key = conf.StripeKey.Reveal()
+//
+// Values are decoded like any other field of type T, and values in
+// decode errors are redacted as if the field was tagged secret.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a [Secret] holding value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Reveal returns the value.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// String returns a redacted placeholder.
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+// GoString returns a redacted placeholder for %#v.
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Format writes a redacted placeholder for every verb, so even %d or %x
+// can't reveal the value.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, redacted)
+}
+
+// MarshalJSON returns a redacted placeholder.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+func (s *Secret[T]) valuePtr() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// secretField is implemented by pointers to [Secret] fields.
+type secretField interface {
+	valuePtr() reflect.Value
+}
+
+var secretFieldType = reflect.TypeOf((*secretField)(nil)).Elem()
+
+// isSecretType reports whether t is a [Secret].
+func isSecretType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(secretFieldType)
+}
+
+// holdsSecret reports whether t is a [Secret], or a pointer, [Optional],
+// slice, array or map holding one, so reports know to redact it.
+func holdsSecret(t reflect.Type) bool {
+	for {
+		switch {
+		case t.Kind() == reflect.Map && holdsSecret(t.Key()):
+			return true
+		case t.Kind() == reflect.Pointer, t.Kind() == reflect.Slice, t.Kind() == reflect.Array, t.Kind() == reflect.Map:
+			t = t.Elem()
+		case isOptionalType(t):
+			t = reflect.New(t).Interface().(optionalField).optionalValue().Type()
+		default:
+			return isSecretType(t)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package dotconfig_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+func TestSecret(t *testing.T) {
+	type secretConfig struct {
+		APIKey dotconfig.Secret[string] `env:"SECRET_API_KEY"`
+		Pin    dotconfig.Secret[int]    `env:"SECRET_PIN"`
+	}
+	config, err := dotconfig.FromReader[secretConfig](strings.NewReader("SECRET_API_KEY=abc123\nSECRET_PIN=1234"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.APIKey.Reveal() != "abc123" || config.Pin.Reveal() != 1234 {
+		t.Fatalf("Unexpected values: %v, %v", config.APIKey.Reveal(), config.Pin.Reveal())
+	}
+
+	printed := []string{
+		fmt.Sprint(config),
+		fmt.Sprintf("%v %+v %#v %s %d %x", config, config, config, config.APIKey, config.Pin, config.Pin),
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	printed = append(printed, string(b))
+	for _, info := range dotconfig.Explain(config) {
+		printed = append(printed, info.Value)
+	}
+	for _, s := range printed {
+		if strings.Contains(s, "abc123") || strings.Contains(s, "1234") || strings.Contains(s, "4d2") {
+			t.Errorf("Secret leaked: %v", s)
+		}
+	}
+
+	// Values are redacted from decode errors too.
+	type strictConfig struct {
+		Port dotconfig.Secret[int] `env:"SECRET_PORT,autobase"`
+	}
+	_, err = dotconfig.FromReader[strictConfig](strings.NewReader("SECRET_PORT=hunter2"))
+	if !errors.Is(dotconfig.Errors(err)[0], dotconfig.ErrInvalidValue) || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected redacted invalid value error. Got %v.", err)
+	}
+}
+
+func TestSecretWrapped(t *testing.T) {
+	type wrappedConfig struct {
+		Token    *dotconfig.Secret[string]                    `env:"SECRET_WRAPPED_TOKEN"`
+		Password dotconfig.Optional[dotconfig.Secret[string]] `env:"SECRET_WRAPPED_PASSWORD"`
+	}
+	config, err := dotconfig.FromReader[wrappedConfig](strings.NewReader("SECRET_WRAPPED_TOKEN=tok-abc123\nSECRET_WRAPPED_PASSWORD=pw-abc123"))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	password := config.Password.Value()
+	if config.Token.Reveal() != "tok-abc123" || password.Reveal() != "pw-abc123" {
+		t.Fatalf("Unexpected values: %v, %v", config.Token.Reveal(), password.Reveal())
+	}
+
+	infos := dotconfig.Explain(config)
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 fields. Got %v.", len(infos))
+	}
+	printed := []string{}
+	for _, info := range infos {
+		if !info.Secret {
+			t.Errorf("Expected %v to be secret.", info.Key)
+		}
+		printed = append(printed, info.Value)
+	}
+	b, err := dotconfig.MarshalJSON(config)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	printed = append(printed, string(b))
+	for _, s := range printed {
+		if strings.Contains(s, "abc123") {
+			t.Errorf("Secret leaked: %v", s)
+		}
+	}
+}
+
+func TestSecretContainers(t *testing.T) {
+	type containerConfig struct {
+		Keys   []dotconfig.Secret[string]          `env:"SECRET_CONTAINER_KEYS"`
+		Pair   [2]dotconfig.Secret[string]         `env:"SECRET_CONTAINER_PAIR"`
+		Tokens map[string]dotconfig.Secret[string] `env:"SECRET_CONTAINER_TOKENS"`
+	}
+	config, err := dotconfig.FromReader[containerConfig](strings.NewReader("SECRET_CONTAINER_KEYS=s1-abc123,s2-abc123"), dotconfig.WithEnviron(map[string]string{}), dotconfig.AllowPartial)
+	if len(config.Keys) != 2 || config.Keys[1].Reveal() != "s2-abc123" {
+		t.Fatalf("Unexpected keys: %v, %v", len(config.Keys), err)
+	}
+	config.Pair = [2]dotconfig.Secret[string]{dotconfig.NewSecret("p-abc123"), dotconfig.NewSecret("q-abc123")}
+	config.Tokens = map[string]dotconfig.Secret[string]{"a": dotconfig.NewSecret("t-abc123")}
+
+	printed := []string{}
+	for _, info := range dotconfig.Explain(config) {
+		if !info.Secret {
+			t.Errorf("Expected %v to be secret.", info.Key)
+		}
+		printed = append(printed, info.Value)
+	}
+	b, err := dotconfig.MarshalJSON(config)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	printed = append(printed, string(b))
+	for _, s := range printed {
+		if strings.Contains(s, "abc123") {
+			t.Errorf("Secret leaked: %v", s)
+		}
+	}
+}
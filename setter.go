@@ -0,0 +1,38 @@
+package dotconfig
+
+import "reflect"
+
+// Setter is implemented by types that parse their own values from the
+// environment. It's checked before [encoding.TextUnmarshaler], so a type
+// can decode env values differently from how it reads JSON or flags:
+//
+//	type LogLevel int
+//
+//	func (l *LogLevel) SetFromEnv(value string) error {
+//		switch strings.ToLower(value) {
+//		case "debug":
+//			*l = -4
+//		case "", "info":
+//			*l = 0
+//		default:
+//			return fmt.Errorf("unknown log level %q", value)
+//		}
+//		return nil
+//	}
+//
+// An error fails the field with [ErrInvalidValue], with the error as its
+// cause.
+type Setter interface {
+	SetFromEnv(value string) error
+}
+
+// setter returns v as a [Setter] if its pointer implements it. Only
+// addressable values are used, since a setter has to store the value
+// somewhere.
+func setter(v reflect.Value) (Setter, bool) {
+	if !v.CanAddr() {
+		return nil, false
+	}
+	s, ok := v.Addr().Interface().(Setter)
+	return s, ok
+}
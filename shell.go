@@ -0,0 +1,55 @@
+package dotconfig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// shellName matches keys that are valid shell variable names.
+var shellName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WriteShell writes values to w as shell export statements, in sorted
+// order with every value single quoted, so tools can support
+// eval "$(mytool env)" workflows:
+//
+//	export API_KEY='abc123'
+//	export GREETING='it'\''s fine'
+//
+// It returns an error without writing anything if a key isn't a valid
+// shell variable name.
+func WriteShell(w io.Writer, values map[string]string) error {
+	keys := sortedKeys(values)
+	for _, key := range keys {
+		if !shellName.MatchString(key) {
+			return fmt.Errorf("%q isn't a valid shell variable name", key)
+		}
+	}
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "export %v=%v\n", key, shellQuote(values[key]))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteShellConfig calls [WriteShell] with the value of each field in
+// config that has an env tag. Secret fields are included since the
+// output is meant to be evaluated, so be careful where you send it. Pass
+// the options you decoded with so keys and separators match.
+func WriteShellConfig[T any](w io.Writer, config T, opts ...DecodeOption) error {
+	cv := reflect.ValueOf(&config).Elem()
+	if cv.Kind() != reflect.Struct {
+		return ErrConfigMustBeStruct
+	}
+	return WriteShell(w, optsFromVariadic(opts).environ(cv))
+}
+
+// shellQuote single quotes s. Nothing is special inside single quotes,
+// so the only thing to handle is single quotes themselves: end the
+// quoted string, add an escaped quote, and start a new one.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,113 @@
+package dotconfig_test
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+func TestWriteShell(t *testing.T) {
+	var buf bytes.Buffer
+	err := dotconfig.WriteShell(&buf, map[string]string{
+		"GREETING": "it's $HOME `here`",
+		"API_KEY":  "abc123",
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := "export API_KEY='abc123'\nexport GREETING='it'\\''s $HOME `here`'\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := dotconfig.WriteShell(&buf, map[string]string{"BAD KEY": "x"}); err == nil || buf.Len() != 0 {
+		t.Errorf("Expected error and no output for invalid key. Got %v and %q.", err, buf.String())
+	}
+}
+
+func TestWriteShellConfig(t *testing.T) {
+	type shellConfig struct {
+		Host    net.IP   `env:"HOST"`
+		Port    int      `env:"PORT"`
+		Debug   bool     `env:"DEBUG"`
+		Regions []string `env:"REGIONS" sep:";"`
+		Token   string   `env:"TOKEN,secret"`
+		Ignored string
+	}
+	config := shellConfig{Host: net.IPv4(10, 0, 0, 1), Port: 8080, Regions: []string{"us", "eu"}, Token: "abc123"}
+	var buf bytes.Buffer
+	if err := dotconfig.WriteShellConfig(&buf, config); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := `export DEBUG='false'
+export HOST='10.0.0.1'
+export PORT='8080'
+export REGIONS='us;eu'
+export TOKEN='abc123'
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, buf.String())
+	}
+}
+
+func TestExecEnv(t *testing.T) {
+	type execConfig struct {
+		DatabaseURL string   `env:"DATABASE_URL"`
+		WorkerPort  int      `env:"WORKER_PORT"`
+		Regions     []string `env:"REGIONS" sep:";"`
+		AdminToken  string   `env:"ADMIN_TOKEN,secret"`
+	}
+	config := execConfig{DatabaseURL: "postgres://db/app", WorkerPort: 9000, Regions: []string{"us", "eu"}, AdminToken: "abc123"}
+	env, err := dotconfig.ExecEnv(config, nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []string{"ADMIN_TOKEN=abc123", "DATABASE_URL=postgres://db/app", "REGIONS=us;eu", "WORKER_PORT=9000"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, env)
+	}
+
+	env, err = dotconfig.ExecEnv(config, map[string]string{"DatabaseURL": "", "WorkerPort": "PORT"})
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected = []string{"DATABASE_URL=postgres://db/app", "PORT=9000"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, env)
+	}
+
+	if _, err := dotconfig.ExecEnv(config, map[string]string{"Missing": ""}); err == nil {
+		t.Errorf("Expected error for unknown field.")
+	}
+	if _, err := dotconfig.ExecEnv(config, map[string]string{"DatabaseURL": "X", "WorkerPort": "X"}); err == nil {
+		t.Errorf("Expected error for duplicate key.")
+	}
+	// Feature flags pass a key per flag, and Lazy fields aren't fetched.
+	type flagsConfig struct {
+		Features dotconfig.FeatureFlags `env:"FEATURE_"`
+		Key      dotconfig.Lazy[string] `env:"EXEC_LAZY_KEY"`
+	}
+	env, err = dotconfig.ExecEnv(flagsConfig{Features: dotconfig.FeatureFlags{"beta": true, "dark_mode": false}}, nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected = []string{"FEATURE_BETA=true", "FEATURE_DARK_MODE=false"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, env)
+	}
+	env, err = dotconfig.ExecEnv(flagsConfig{Features: dotconfig.FeatureFlags{"beta": true}}, map[string]string{"Features": "FLAG_"})
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected = []string{"FLAG_BETA=true"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v. Got %v.", expected, env)
+	}
+	if _, err := dotconfig.ExecEnv(flagsConfig{}, map[string]string{"Key": ""}); err == nil {
+		t.Errorf("Expected error for Lazy field.")
+	}
+}
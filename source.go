@@ -0,0 +1,200 @@
+package dotconfig
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Source is a backend that supplies key/value pairs, such as a secret
+// manager (Vault, SSM) or an HTTP endpoint. Values fetched from a Source
+// are set in the environment and decoded the same way as values read
+// from a file.
+type Source interface {
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// SourceFunc is an adapter to allow the use of ordinary functions as a
+// [Source].
+type SourceFunc func(ctx context.Context) (map[string]string, error)
+
+// Fetch calls f(ctx).
+func (f SourceFunc) Fetch(ctx context.Context) (map[string]string, error) {
+	return f(ctx)
+}
+
+// Names of the built-in backends for source tags. Sources are named
+// with [NamedSource].
+const (
+	SourceFile = "file" // An env file loaded by functions like [FromFileName]
+	SourceEnv  = "env"  // The environment, when the key wasn't set by the load
+	SourceFlag = "flag" // A command-line flag, see [WithFlags]
+)
+
+// NamedSource gives src a name for use in source tags. A field tagged
+// `source:"vault"` is only set from a source named "vault", so a local
+// environment variable can't shadow a credential Vault manages:
+//
+//	type AppConfig struct {
+//		DBPassword string `env:"DB_PASSWORD" source:"vault"`
+//	}
+//
+//	conf, err := dotconfig.FromSources[AppConfig](ctx, []dotconfig.Source{
+//		dotconfig.NamedSource("vault", vault),
+//	})
+//
+// Fields can also be pinned to [SourceFile], [SourceEnv] or [SourceFlag].
+// Unnamed sources are called "source". A Source that wraps another one
+// keeps its name if it has an Unwrap method returning it:
+//
+//	func (s tracedSource) Unwrap() dotconfig.Source { return s.src }
+func NamedSource(name string, src Source) Source {
+	return namedSource{name: name, Source: src}
+}
+
+type namedSource struct {
+	name string
+	Source
+}
+
+// sourceName returns the name of src from [NamedSource].
+func sourceName(src Source) string {
+	switch s := src.(type) {
+	case namedSource:
+		return s.name
+	case timeoutSource:
+		return sourceName(s.Source)
+	case interface{ Unwrap() Source }:
+		return sourceName(s.Unwrap())
+	}
+	return "source"
+}
+
+// keySource returns the name of the backend that key's current value
+// came from.
+func (o options) keySource(key string, res *Result) string {
+	if _, ok := o.lookupFlag(key); ok {
+		return SourceFlag
+	}
+	if source, ok := res.sources[key]; ok {
+		return source
+	}
+	return SourceEnv
+}
+
+// FromSource fetches values from src, sets them in the environment,
+// and decodes them into a T. Keys are set in sorted order.
+func FromSource[T any](ctx context.Context, src Source, opts ...DecodeOption) (T, error) {
+	return FromSources[T](ctx, []Source{src}, opts...)
+}
+
+// FromSources is like [FromSource] but fetches from all of sources at
+// once. Values are still merged in order, so values from later sources
+// override earlier ones. If a fetch fails, the others are canceled and
+// the first error is returned.
+func FromSources[T any](ctx context.Context, sources []Source, opts ...DecodeOption) (T, error) {
+	ops := optsFromVariadic(opts)
+	if ops.LoadTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ops.LoadTimeout)
+		defer cancel()
+	}
+	fetched, err := ops.fetchAll(ctx, sources)
+	if err != nil {
+		var config T
+		return config, err
+	}
+	res := ops.newResult()
+	for i, src := range sources {
+		values := fetched[i]
+		for _, key := range sortedKeys(values) {
+			if ops.shouldSet(key, &res) {
+				res.setenv(key, values[key], sourceName(src), 0)
+			}
+		}
+	}
+	return fromEnv[T](ops, &res)
+}
+
+// fetchAll fetches from each of sources concurrently and returns their
+// values in the same order. Startup time is then the slowest source
+// rather than the sum of them.
+func (o options) fetchAll(ctx context.Context, sources []Source) ([]map[string]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fetched := make([]map[string]string, len(sources))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values, err := o.fetch(ctx, src)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			fetched[i] = values
+		}()
+	}
+	wg.Wait()
+	return fetched, firstErr
+}
+
+// defaultRefreshInterval is used by [WatchSource] when no
+// [RefreshInterval] option is supplied.
+const defaultRefreshInterval = 5 * time.Minute
+
+// RefreshInterval sets how often [WatchSource] fetches values from its
+// source. The default is 5 minutes.
+func RefreshInterval(d time.Duration) DecodeOption {
+	return funcOption(func(o *options) {
+		o.RefreshInterval = d
+	})
+}
+
+// WatchSource calls [FromSource] on a timer and delivers the result to
+// onChange, the same way [Watch] does for files. Use the
+// [RefreshInterval] option to control how often values are fetched:
+//
+//	err := dotconfig.WatchSource(ctx, vault, func(conf AppConfig, err error) {
+//		// ...
+//	}, dotconfig.RefreshInterval(time.Minute))
+//
+// WatchSource returns immediately and stops refreshing when ctx is done.
+func WatchSource[T any](ctx context.Context, src Source, onChange func(T, error), opts ...DecodeOption) error {
+	interval := optsFromVariadic(opts).RefreshInterval
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				onChange(FromSource[T](ctx, src, opts...))
+			}
+		}
+	}()
+	return nil
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,486 @@
+package dotconfig_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+type sourceConfig struct {
+	Token string `env:"SOURCE_TOKEN"`
+}
+
+func TestFromSource(t *testing.T) {
+	src := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"SOURCE_TOKEN": "abc123"}, nil
+	})
+	config, err := dotconfig.FromSource[sourceConfig](context.Background(), src)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Token != "abc123" {
+		t.Errorf("Expected token from source. Got %q.", config.Token)
+	}
+
+	fetchErr := errors.New("source unavailable")
+	src = dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return nil, fetchErr
+	})
+	_, err = dotconfig.FromSource[sourceConfig](context.Background(), src)
+	if !errors.Is(err, fetchErr) {
+		t.Errorf("Expected error: %v. Got: %v.", fetchErr, err)
+	}
+}
+
+func TestWatchSource(t *testing.T) {
+	fetches := 0
+	src := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		fetches++
+		if fetches == 1 {
+			return map[string]string{"SOURCE_TOKEN": "first"}, nil
+		}
+		return map[string]string{"SOURCE_TOKEN": "second"}, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan sourceConfig)
+	err := dotconfig.WatchSource(ctx, src, func(config sourceConfig, err error) {
+		if err != nil {
+			t.Errorf("Didn't expect error. Got %v.", err)
+			return
+		}
+		select {
+		case changes <- config:
+		case <-ctx.Done():
+		}
+	}, dotconfig.RefreshInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case config := <-changes:
+			if config.Token != expected {
+				t.Errorf("Expected %q. Got %q.", expected, config.Token)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for refresh.")
+		}
+	}
+}
+
+func TestEnvDir(t *testing.T) {
+	type envDirConfig struct {
+		Region string `env:"ENVDIR_REGION"`
+		Cert   string `env:"ENVDIR_CERT"`
+		Unset  string `env:"ENVDIR_UNSET,optional"`
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"ENVDIR_REGION": "us-west-2\n",
+		"ENVDIR_CERT":   "line one\x00line two",
+		"ENVDIR_UNSET":  "",
+		".hidden":       "ignored",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config, err := dotconfig.FromSource[envDirConfig](context.Background(), dotconfig.EnvDir(dir))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := envDirConfig{Region: "us-west-2", Cert: "line one\nline two"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+}
+
+func TestSourceTag(t *testing.T) {
+	type pinnedConfig struct {
+		Password string `env:"PINNED_PASSWORD" source:"vault"`
+		Region   string `env:"PINNED_REGION"`
+		Token    string `env:"PINNED_TOKEN,optional" source:"vault"`
+	}
+	vault := dotconfig.NamedSource("vault", dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"PINNED_PASSWORD": "from-vault"}, nil
+	}))
+	local := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"PINNED_REGION": "us-west-2"}, nil
+	})
+	// A stray environment variable shouldn't shadow the managed values.
+	t.Setenv("PINNED_TOKEN", "from-env")
+	config, err := dotconfig.FromSources[pinnedConfig](context.Background(), []dotconfig.Source{vault, local})
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := pinnedConfig{Password: "from-vault", Region: "us-west-2"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	// Values from the wrong backend are treated as missing.
+	_, err = dotconfig.FromReader[pinnedConfig](strings.NewReader("PINNED_PASSWORD=from-file\nPINNED_REGION=us-west-2"))
+	if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	type fromConfig struct {
+		Password string `env:"FROM_PASSWORD,from=FROM_PASSWORD_REF"`
+		APIKey   string `env:"FROM_API_KEY,from=FROM_API_KEY_REF"`
+		Region   string `env:"FROM_REGION,from=FROM_REGION_REF"`
+	}
+	if err := dotconfig.ValidateStruct[fromConfig](); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	secret := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vault := dotconfig.ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+		if ref.Host+ref.Path != "secret/api" || ref.Fragment != "key" {
+			return "", fmt.Errorf("no secret at %v", ref)
+		}
+		return "abc123", nil
+	})
+	env := "FROM_PASSWORD_REF=file://" + secret + "\nFROM_API_KEY_REF=vault://secret/api#key\nFROM_REGION=us-west-2\n"
+	// Loads use their own environment so cases can't see each other's
+	// keys.
+	config, err := dotconfig.FromReader[fromConfig](strings.NewReader(env), dotconfig.WithResolver("vault", vault), dotconfig.WithEnviron(map[string]string{}))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := fromConfig{Password: "s3cret", APIKey: "abc123", Region: "us-west-2"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	for _, env := range []string{
+		// No resolver for the scheme.
+		"FROM_PASSWORD=x\nFROM_API_KEY_REF=ssm://api/key\nFROM_REGION=us-west-2",
+		// The resolver fails.
+		"FROM_PASSWORD=x\nFROM_API_KEY_REF=vault://secret/other#key\nFROM_REGION=us-west-2",
+		// Both keys are set.
+		"FROM_PASSWORD=x\nFROM_API_KEY=y\nFROM_API_KEY_REF=vault://secret/api#key\nFROM_REGION=us-west-2",
+	} {
+		_, err := dotconfig.FromReader[fromConfig](strings.NewReader(env), dotconfig.WithResolver("vault", vault), dotconfig.WithEnviron(map[string]string{}))
+		if errs := dotconfig.Errors(err); len(errs) != 1 || !errors.Is(errs[0], dotconfig.ErrInvalidValue) {
+			t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidValue, err)
+		}
+	}
+
+	type badFromConfig struct {
+		Password string `env:"BAD_FROM_PASSWORD,from"`
+		APIKey   string `env:"BAD_FROM_API_KEY,secret=yes"`
+	}
+	err = dotconfig.ValidateStruct[badFromConfig]()
+	if errs := dotconfig.Errors(err); len(errs) != 2 || !errors.Is(errs[0], dotconfig.ErrInvalidTag) || !errors.Is(errs[1], dotconfig.ErrInvalidTag) {
+		t.Errorf("Expected 2 errors: %v. Got: %v.", dotconfig.ErrInvalidTag, err)
+	}
+}
+
+func TestRefreshTTL(t *testing.T) {
+	type ttlConfig struct {
+		Region   string `env:"TTL_REGION"`
+		Password string `env:"TTL_PASSWORD" ttl:"10ms"`
+	}
+	var fetches atomic.Int32
+	src := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		n := fetches.Add(1)
+		return map[string]string{
+			"TTL_REGION":   fmt.Sprintf("region-%v", n),
+			"TTL_PASSWORD": fmt.Sprintf("password-%v", n),
+		}, nil
+	})
+	config, err := dotconfig.FromSource[ttlConfig](context.Background(), src)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	store := dotconfig.NewStore(config)
+	changed := make(chan ttlConfig, 1)
+	store.OnChange(func(old, new ttlConfig, changes dotconfig.Changes) {
+		select {
+		case changed <- new:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := dotconfig.RefreshTTL(ctx, store, src); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	select {
+	case config := <-changed:
+		// Only the field with a TTL is refreshed.
+		if config.Region != "region-1" || config.Password == "password-1" {
+			t.Errorf("Unexpected config after refresh: %#v", config)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for refresh.")
+	}
+
+	// Groups refresh on their own without undoing each other, and other
+	// fields don't have to be in the environment.
+	type groupsConfig struct {
+		Region   string `env:"TTL_GROUPS_REGION"`
+		Password string `env:"TTL_PASSWORD" ttl:"5ms"`
+		Token    string `env:"TTL_TOKEN" ttl:"7ms"`
+	}
+	tokens := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		n := fetches.Add(1)
+		return map[string]string{"TTL_PASSWORD": fmt.Sprintf("password-%v", n), "TTL_TOKEN": fmt.Sprintf("token-%v", n)}, nil
+	})
+	groupsStore := dotconfig.NewStore(groupsConfig{Region: "us-west-2"})
+	refreshed := make(chan groupsConfig, 1)
+	groupsStore.OnChange(func(old, new groupsConfig, changes dotconfig.Changes) {
+		if new.Password != "" && new.Token != "" {
+			select {
+			case refreshed <- new:
+			default:
+			}
+		}
+	})
+	groupsStore.OnError(func(err error) {
+		t.Errorf("Didn't expect error. Got %v.", err)
+	})
+	if err := dotconfig.RefreshTTL(ctx, groupsStore, tokens, dotconfig.WithEnviron(map[string]string{})); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	select {
+	case config := <-refreshed:
+		if config.Region != "us-west-2" {
+			t.Errorf("Unexpected config after refresh: %#v", config)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for refresh.")
+	}
+	cancel()
+
+	type badTTLConfig struct {
+		Password string `env:"TTL_PASSWORD" ttl:"soon"`
+	}
+	err = dotconfig.RefreshTTL(ctx, dotconfig.NewStore(badTTLConfig{}), src)
+	if !errors.Is(err, dotconfig.ErrInvalidTag) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrInvalidTag, err)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	type lazyConfig struct {
+		Region    string                   `env:"LAZY_REGION"`
+		Key       dotconfig.Lazy[string]   `env:"LAZY_KEY,secret"`
+		Port      dotconfig.Lazy[int]      `env:"LAZY_PORT" default:"8080"`
+		Missing   dotconfig.Lazy[string]   `env:"LAZY_MISSING"`
+		Unfetched dotconfig.Lazy[[]string] `env:"LAZY_UNFETCHED,optional"`
+	}
+	if err := dotconfig.ValidateStruct[lazyConfig](); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	var fetches atomic.Int32
+	src := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		fetches.Add(1)
+		return map[string]string{"LAZY_KEY": "abc123"}, nil
+	})
+	config, err := dotconfig.FromReader[lazyConfig](strings.NewReader("LAZY_REGION=us-west-2"), dotconfig.LazySource(src))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if n := fetches.Load(); n != 0 {
+		t.Fatalf("Expected no fetches before Get. Got %v.", n)
+	}
+	// Copies share the fetched value.
+	copied := config
+	for _, c := range []lazyConfig{config, copied} {
+		if key, err := c.Key.Get(); err != nil || key != "abc123" {
+			t.Errorf("Expected abc123. Got %v, %v.", key, err)
+		}
+	}
+	if port, err := config.Port.Get(); err != nil || port != 8080 {
+		t.Errorf("Expected default 8080. Got %v, %v.", port, err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("Expected 2 fetches. Got %v.", n)
+	}
+	if _, err := config.Missing.Get(); !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Errorf("Expected error: %v. Got: %v.", dotconfig.ErrMissingEnvVar, err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	type retryConfig struct {
+		Token string `env:"RETRY_TOKEN"`
+	}
+	calls := 0
+	flaky := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("connection reset")
+		}
+		return map[string]string{"RETRY_TOKEN": "abc"}, nil
+	})
+	config, err := dotconfig.FromSource[retryConfig](context.Background(), flaky, dotconfig.Retry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if config.Token != "abc" || calls != 3 {
+		t.Errorf("Expected abc after 3 calls. Got %q after %v.", config.Token, calls)
+	}
+
+	calls = 0
+	_, err = dotconfig.FromSource[retryConfig](context.Background(), flaky, dotconfig.Retry(2, time.Millisecond))
+	if err == nil || err.Error() != "connection reset" || calls != 2 {
+		t.Errorf("Expected connection reset after 2 calls. Got %v after %v.", err, calls)
+	}
+}
+
+func TestSourceTimeouts(t *testing.T) {
+	type timeoutConfig struct {
+		Token string `env:"TIMEOUT_TOKEN"`
+	}
+	// A source that ignores ctx and never returns.
+	hung := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		select {}
+	})
+	src := dotconfig.TimeoutSource(dotconfig.NamedSource("vault", hung), 10*time.Millisecond)
+	_, err := dotconfig.FromSource[timeoutConfig](context.Background(), src)
+	if !errors.Is(err, context.DeadlineExceeded) || err.Error() != "source vault timed out after 10ms: context deadline exceeded" {
+		t.Errorf("Expected vault to time out. Got %v.", err)
+	}
+
+	_, err = dotconfig.FromSource[timeoutConfig](context.Background(), dotconfig.NamedSource("metadata", hung), dotconfig.LoadTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) || err.Error() != "source metadata timed out: context deadline exceeded" {
+		t.Errorf("Expected metadata to time out. Got %v.", err)
+	}
+}
+
+func TestFromSourcesConcurrent(t *testing.T) {
+	type concurrentConfig struct {
+		Region string `env:"CONCURRENT_REGION"`
+		Token  string `env:"CONCURRENT_TOKEN"`
+	}
+	slow := func(values map[string]string) dotconfig.Source {
+		return dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+			time.Sleep(50 * time.Millisecond)
+			return values, nil
+		})
+	}
+	sources := []dotconfig.Source{
+		slow(map[string]string{"CONCURRENT_REGION": "us-east-1", "CONCURRENT_TOKEN": "first"}),
+		slow(map[string]string{"CONCURRENT_TOKEN": "second"}),
+		slow(map[string]string{"CONCURRENT_REGION": "us-west-2"}),
+	}
+	start := time.Now()
+	config, err := dotconfig.FromSources[concurrentConfig](context.Background(), sources)
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Errorf("Expected sources to be fetched concurrently. Took %v.", elapsed)
+	}
+	expected := concurrentConfig{Region: "us-west-2", Token: "second"}
+	if config != expected {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expected, config)
+	}
+
+	// The first failure cancels the other fetches.
+	failing := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return nil, errors.New("access denied")
+	})
+	waiting := dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_, err = dotconfig.FromSources[concurrentConfig](context.Background(), []dotconfig.Source{waiting, failing})
+	if err == nil || err.Error() != "access denied" {
+		t.Errorf("Expected access denied. Got %v.", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	type metricsConfig struct {
+		Port    int    `env:"METRICS_PORT" default:"8080"`
+		Debug   bool   `env:"METRICS_DEBUG,optional"`
+		Region  string `env:"METRICS_REGION" deprecated:"METRICS_OLD_REGION"`
+		Timeout string `env:"METRICS_TIMEOUT,optional"`
+	}
+	var events []string
+	metrics := dotconfig.Metrics{
+		DefaultApplied: func(field, key string) {
+			events = append(events, "default "+key)
+		},
+		OptionalMissing: func(field, key string) {
+			events = append(events, "optional "+key)
+		},
+		DeprecatedKeyUsed: func(oldKey, key string) {
+			events = append(events, "deprecated "+oldKey)
+		},
+		SourceFetched: func(source string, d time.Duration, err error) {
+			events = append(events, fmt.Sprintf("fetched %v %v", source, err))
+		},
+	}
+	src := dotconfig.NamedSource("vault", dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"METRICS_OLD_REGION": "us-west-2", "METRICS_TIMEOUT": "5s"}, nil
+	}))
+	_, err := dotconfig.FromSource[metricsConfig](context.Background(), src, dotconfig.WithMetrics(metrics))
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	expected := []string{"fetched vault <nil>", "default METRICS_PORT", "optional METRICS_DEBUG", "deprecated METRICS_OLD_REGION"}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%v\nGot:\n%v", expected, events)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	type auditConfig struct {
+		Token  string `env:"AUDIT_TOKEN"`
+		Port   int    `env:"AUDIT_PORT" default:"8080"`
+		Region string `env:"AUDIT_REGION"`
+	}
+	src := dotconfig.NamedSource("vault", dotconfig.SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		return map[string]string{"AUDIT_TOKEN": "hunter2"}, nil
+	}))
+	var buf bytes.Buffer
+	_, err := dotconfig.FromSource[auditConfig](context.Background(), src, dotconfig.AuditLog(&buf))
+	if !errors.Is(err, dotconfig.ErrMissingEnvVar) {
+		t.Fatalf("Expected ErrMissingEnvVar. Got %v.", err)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatalf("Audit log shouldn't contain values. Got %v.", buf.String())
+	}
+	var rec dotconfig.AuditRecord
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if rec.Started.IsZero() || rec.Finished.Before(rec.Started) {
+		t.Errorf("Expected started and finished times. Got %v and %v.", rec.Started, rec.Finished)
+	}
+	expectedKeys := []dotconfig.AuditKey{
+		{Field: "Token", Key: "AUDIT_TOKEN", Source: "vault"},
+		{Field: "Port", Key: "AUDIT_PORT", Source: "default"},
+	}
+	if !reflect.DeepEqual(rec.Keys, expectedKeys) {
+		t.Errorf("Expected:\n%#v\nGot:\n%#v", expectedKeys, rec.Keys)
+	}
+	if !reflect.DeepEqual(rec.Sources, []string{"vault", "default"}) {
+		t.Errorf("Expected vault and default sources. Got %v.", rec.Sources)
+	}
+	if !reflect.DeepEqual(rec.Failed, []string{"AUDIT_REGION"}) {
+		t.Errorf("Expected AUDIT_REGION to fail. Got %v.", rec.Failed)
+	}
+}
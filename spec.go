@@ -0,0 +1,262 @@
+package dotconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseSpec parses r the way the Ruby and Node dotenv libraries do for
+// the [DotenvSpec] option:
+//
+//   - Lines can start with "export" and use ":" instead of "=".
+//   - Single quoted and backtick quoted values are literal.
+//   - Double quoted values support \n, \r, \t and backslash escapes.
+//   - Quoted values can span lines.
+//   - Unquoted values are trimmed and end at a "#".
+//   - $VAR, ${VAR} and ${VAR:-default} are expanded in unquoted and
+//     double quoted values, using earlier lines and then the
+//     environment. \$ is a literal dollar sign.
+//
+// The cases in testdata/dotenv-spec describe where those libraries agree,
+// so add to them when changing this.
+func parseSpec(r io.Reader, o options) ([]Entry, []Warning, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	src, err := o.normalizeSource(string(b))
+	if err != nil {
+		return nil, nil, err
+	}
+	p := specParser{src: src, line: 1, defined: map[string]string{}, getenv: o.getenv, max: o.MaxLineLength}
+	if p.max <= 0 {
+		p.max = defaultMaxLineLength
+	}
+	for p.skipBlank(); p.pos < len(p.src) && p.err == nil; p.skipBlank() {
+		p.parseLine()
+	}
+	return p.entries, p.warnings, p.err
+}
+
+// specParser holds the state for parseSpec.
+type specParser struct {
+	src      string
+	pos      int
+	line     int
+	entries  []Entry
+	warnings []Warning
+	// defined holds values set earlier in the file for expansion.
+	defined map[string]string
+	// getenv looks up values that weren't defined in the file.
+	getenv func(string) (string, bool)
+	// max is the longest a value can get from expanding variables.
+	// Without it, each line could double the length of the last.
+	max int
+	err error
+}
+
+// skipBlank skips whitespace, blank lines and comment lines.
+func (p *specParser) skipBlank() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+// skipLine moves to the start of the next line.
+func (p *specParser) skipLine() {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+// skipSpaces skips spaces and tabs on the current line.
+func (p *specParser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// parseLine parses one KEY=value assignment, which may span lines if the
+// value is quoted.
+func (p *specParser) parseLine() {
+	line := p.line
+	if rest, ok := strings.CutPrefix(p.src[p.pos:], "export"); ok && len(rest) > 0 && (rest[0] == ' ' || rest[0] == '\t') {
+		p.pos += len("export")
+		p.skipSpaces()
+	}
+	start := p.pos
+	for p.pos < len(p.src) && isSpecKeyByte(p.src[p.pos]) {
+		p.pos++
+	}
+	key := p.src[start:p.pos]
+	p.skipSpaces()
+	if key == "" || p.pos >= len(p.src) || (p.src[p.pos] != '=' && p.src[p.pos] != ':') {
+		p.warnings = append(p.warnings, Warning{Kind: WarnMalformedLine, Line: line, Message: "skipped line that isn't KEY=value"})
+		p.skipLine()
+		return
+	}
+	p.pos++
+	start = p.pos
+	p.skipSpaces()
+	value := p.value()
+	if len(value) > p.max {
+		p.err = &ParseError{Line: line, Err: fmt.Errorf("expanding %v: %w (see dotconfig.MaxLineLength)", key, bufio.ErrTooLong)}
+		return
+	}
+	p.defined[key] = value
+	p.entries = append(p.entries, Entry{Key: key, Value: value, Raw: p.src[start:p.pos], Line: line})
+}
+
+// value reads a quoted or unquoted value and anything after it on the
+// line.
+func (p *specParser) value() string {
+	if p.pos < len(p.src) {
+		if q := p.src[p.pos]; q == '\'' || q == '"' || q == '`' {
+			if end, ok := p.closingQuote(q); ok {
+				raw := p.src[p.pos+1 : end]
+				p.line += strings.Count(raw, "\n")
+				p.pos = end + 1
+				// Anything after the closing quote is ignored, which is
+				// usually a comment.
+				p.skipLine()
+				switch q {
+				case '"':
+					return p.expand(unescapeSpec(raw))
+				default:
+					// Single quotes and backticks are literal.
+					return raw
+				}
+			}
+		}
+	}
+	// Unquoted values end at a comment or the end of the line.
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '#' {
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.src[start:p.pos])
+	p.skipLine()
+	return p.expand(raw)
+}
+
+// closingQuote returns the index of the quote that closes the one at
+// p.pos, skipping quotes escaped with a backslash.
+func (p *specParser) closingQuote(q byte) (int, bool) {
+	for i := p.pos + 1; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case q:
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// unescapeSpec handles escapes in double quoted values: \n, \r and \t
+// are control characters, and a backslash before anything else is
+// dropped so \" and \\ work.
+func unescapeSpec(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '$':
+			// Keep the escape so expand leaves the dollar sign alone.
+			b.WriteString(`\$`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// expand replaces $VAR, ${VAR} and ${VAR:-default} with values defined
+// earlier in the file, falling back to the environment. \$ is a literal
+// dollar sign.
+func (p *specParser) expand(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], `\$`):
+			b.WriteByte('$')
+			i++
+		case s[i] == '$' && strings.HasPrefix(s[i+1:], "{"):
+			end := strings.Index(s[i:], "}")
+			if end < 0 {
+				b.WriteString(s[i:])
+				return b.String()
+			}
+			name, fallback, hasFallback := strings.Cut(s[i+2:i+end], ":-")
+			value := p.lookup(name)
+			if value == "" && hasFallback {
+				value = fallback
+			}
+			b.WriteString(value)
+			i += end
+			if b.Len() > p.max {
+				return b.String()
+			}
+		case s[i] == '$':
+			j := i + 1
+			for j < len(s) && isSpecNameByte(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				b.WriteByte('$')
+				continue
+			}
+			b.WriteString(p.lookup(s[i+1 : j]))
+			i = j - 1
+			if b.Len() > p.max {
+				return b.String()
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// lookup returns the value of name from earlier in the file or the
+// environment.
+func (p *specParser) lookup(name string) string {
+	if value, ok := p.defined[name]; ok {
+		return value
+	}
+	value, _ := p.getenv(name)
+	return value
+}
+
+// isSpecKeyByte reports whether c can be part of a key.
+func isSpecKeyByte(c byte) bool {
+	return isSpecNameByte(c) || c == '.' || c == '-'
+}
+
+// isSpecNameByte reports whether c can be part of a variable name in an
+// expansion.
+func isSpecNameByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,20 @@
+package dotconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// isSQLNull reports whether t is one of the nullable types from
+// database/sql, like sql.NullString or sql.Null[T]. They're all a value
+// followed by a Valid bool. Missing keys leave them invalid (NULL)
+// instead of being an error, so configs can flow straight into
+// database parameters.
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		t.PkgPath() == "database/sql" &&
+		strings.HasPrefix(t.Name(), "Null") &&
+		t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" &&
+		t.Field(1).Type.Kind() == reflect.Bool
+}
@@ -0,0 +1,123 @@
+package dotconfig
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds the latest config and is safe for concurrent use. It lets
+// handlers read the current config without locks while [Watch] or
+// [WatchSource] swap in new values:
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env")
+//	// handle err
+//	store := dotconfig.NewStore(conf)
+//	err = dotconfig.Watch(ctx, ".env", store.Update)
+//	// ...
+//	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+//		conf := store.Get()
+//		// ...
+//	})
+//
+// The zero value is ready to use and holds the zero value of T.
+type Store[T any] struct {
+	p atomic.Pointer[T]
+
+	mu             sync.Mutex
+	listeners      []func(old, new T, changes Changes)
+	errorListeners []func(err error)
+}
+
+// Validator can be implemented by config types to reject configs that
+// decoded successfully but aren't usable. [Store.Update] calls Validate
+// before swapping in a new config.
+type Validator interface {
+	Validate() error
+}
+
+// NewStore returns a [Store] holding config.
+func NewStore[T any](config T) *Store[T] {
+	s := &Store[T]{}
+	s.p.Store(&config)
+	return s
+}
+
+// Get returns the current config.
+func (s *Store[T]) Get() T {
+	if p := s.p.Load(); p != nil {
+		return *p
+	}
+	var config T
+	return config
+}
+
+// Swap stores config and returns the previous config.
+func (s *Store[T]) Swap(config T) T {
+	if old := s.p.Swap(&config); old != nil {
+		return *old
+	}
+	var old T
+	return old
+}
+
+// Update has the signature of the onChange callback for [Watch] and
+// [WatchSource]. It swaps in config if err is nil and, when T implements
+// [Validator], config is valid. Otherwise it keeps serving the current
+// config and passes the error to functions registered with
+// [Store.OnError]. Functions registered with [Store.OnChange] are called
+// if any fields changed.
+func (s *Store[T]) Update(config T, err error) {
+	if err == nil {
+		if v, ok := any(config).(Validator); ok {
+			err = v.Validate()
+		}
+	}
+	if err != nil {
+		s.mu.Lock()
+		errorListeners := s.errorListeners
+		s.mu.Unlock()
+		for _, fn := range errorListeners {
+			fn(err)
+		}
+		return
+	}
+	old := s.Swap(config)
+	changes := Diff(old, config)
+	if len(changes) == 0 {
+		return
+	}
+	s.mu.Lock()
+	listeners := s.listeners
+	s.mu.Unlock()
+	for _, fn := range listeners {
+		fn(old, config, changes)
+	}
+}
+
+// OnChange registers fn to be called after [Store.Update] swaps in a
+// config that differs from the previous one. changes holds the names of
+// the fields that changed so subsystems can react selectively:
+//
+//	store.OnChange(func(old, new AppConfig, changes dotconfig.Changes) {
+//		if changes.Contains("DB") {
+//			rebuildPool(new.DB)
+//		}
+//	})
+func (s *Store[T]) OnChange(fn func(old, new T, changes Changes)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// OnError registers fn to be called when [Store.Update] rejects a config
+// because of a decode or validation error. The store keeps serving the
+// previous config, so this is the place to log the problem:
+//
+//	store.OnError(func(err error) {
+//		log.Printf("rejected config reload: %v", err)
+//	})
+func (s *Store[T]) OnError(fn func(err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errorListeners = append(s.errorListeners, fn)
+}
@@ -0,0 +1,128 @@
+package dotconfig_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+type storeConfig struct {
+	Version int
+}
+
+func TestStore(t *testing.T) {
+	var empty dotconfig.Store[storeConfig]
+	if empty.Get().Version != 0 {
+		t.Errorf("Expected zero value from empty store.")
+	}
+
+	store := dotconfig.NewStore(storeConfig{Version: 1})
+	if old := store.Swap(storeConfig{Version: 2}); old.Version != 1 {
+		t.Errorf("Expected old version 1. Got %v.", old.Version)
+	}
+	store.Update(storeConfig{Version: 3}, errors.New("bad reload"))
+	if v := store.Get().Version; v != 2 {
+		t.Errorf("Expected failed update to keep version 2. Got %v.", v)
+	}
+
+	// Concurrent readers and writers should be race free.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			store.Update(storeConfig{Version: i}, nil)
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = store.Get()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStoreOnChange(t *testing.T) {
+	type dbConfig struct {
+		Host string
+	}
+	type appConfig struct {
+		DB       dbConfig
+		LogLevel string
+	}
+	store := dotconfig.NewStore(appConfig{DB: dbConfig{Host: "db1"}, LogLevel: "info"})
+	var got dotconfig.Changes
+	calls := 0
+	store.OnChange(func(old, new appConfig, changes dotconfig.Changes) {
+		calls++
+		got = changes
+	})
+	store.Update(appConfig{DB: dbConfig{Host: "db1"}, LogLevel: "debug"}, nil)
+	if !got.Contains("LogLevel") || got.Contains("DB") {
+		t.Errorf("Expected only LogLevel to change. Got %v.", got)
+	}
+	// Same config again shouldn't notify.
+	store.Update(appConfig{DB: dbConfig{Host: "db1"}, LogLevel: "debug"}, nil)
+	if calls != 1 {
+		t.Errorf("Expected 1 call. Got %v.", calls)
+	}
+}
+
+type validatedConfig struct {
+	Port int
+}
+
+func (c validatedConfig) Validate() error {
+	if c.Port == 0 {
+		return errors.New("port is required")
+	}
+	return nil
+}
+
+func TestStoreRejectsInvalid(t *testing.T) {
+	store := dotconfig.NewStore(validatedConfig{Port: 8080})
+	var errs []error
+	store.OnError(func(err error) {
+		errs = append(errs, err)
+	})
+	store.Update(validatedConfig{}, nil)
+	store.Update(validatedConfig{Port: 9090}, errors.New("decode failed"))
+	if v := store.Get().Port; v != 8080 {
+		t.Errorf("Expected store to keep port 8080. Got %v.", v)
+	}
+	if len(errs) != 2 {
+		t.Errorf("Expected 2 errors. Got %v.", errs)
+	}
+	store.Update(validatedConfig{Port: 9090}, nil)
+	if v := store.Get().Port; v != 9090 {
+		t.Errorf("Expected valid update to swap. Got %v.", v)
+	}
+}
+
+func TestDiffLazy(t *testing.T) {
+	type lazyDB struct {
+		Password dotconfig.Lazy[string] `env:"DIFF_LAZY_DB_PASSWORD,optional"`
+	}
+	type lazyDiffConfig struct {
+		Region string                 `env:"DIFF_LAZY_REGION"`
+		Key    dotconfig.Lazy[string] `env:"DIFF_LAZY_KEY,optional"`
+		DB     lazyDB
+	}
+	load := func(env string) lazyDiffConfig {
+		config, err := dotconfig.FromReader[lazyDiffConfig](strings.NewReader(env), dotconfig.WithEnviron(map[string]string{}))
+		if err != nil {
+			t.Fatalf("Didn't expect error. Got %v.", err)
+		}
+		return config
+	}
+	old := load("DIFF_LAZY_REGION=us-west-2")
+	if changes := dotconfig.Diff(old, load("DIFF_LAZY_REGION=us-west-2")); len(changes) != 0 {
+		t.Errorf("Expected no changes. Got %v.", changes)
+	}
+	changes := dotconfig.Diff(old, load("DIFF_LAZY_REGION=us-east-1"))
+	if len(changes) != 1 || !changes.Contains("Region") {
+		t.Errorf("Expected [Region]. Got %v.", changes)
+	}
+}
@@ -0,0 +1,173 @@
+package dotconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// knownTagOptions are the options allowed after the key in an env tag.
+var knownTagOptions = map[string]bool{
+	"required":  true,
+	"present":   true,
+	"nonzero":   true,
+	"optional":  true,
+	"secret":    true,
+	"autobase":  true,
+	"trim":      true,
+	"notrim":    true,
+	"lower":     true,
+	"upper":     true,
+	"expand":    true,
+	"noexpand":  true,
+	"frozen":    true,
+	"path":      true,
+	"mustexist": true,
+	"export":    true,
+}
+
+// tagValueOptions are the options written as name=value in an env tag.
+var tagValueOptions = map[string]bool{
+	"from": true,
+}
+
+// ValidateStruct checks the tags on config type T without touching the
+// environment: options must be known and not contradict each other,
+// defaults must parse as their field's type, ttl tags must be positive
+// durations, keys must be unique, and field types must be supported,
+// including for the rest field. It's meant to be called from a unit
+// test so tag mistakes are caught in CI instead of at startup:
+//
+//	func TestConfigTags(t *testing.T) {
+//		if err := dotconfig.ValidateStruct[AppConfig](); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func ValidateStruct[T any]() error {
+	var config T
+	ct := reflect.TypeOf(config)
+	if ct == nil || ct.Kind() != reflect.Struct {
+		return ErrConfigMustBeStruct
+	}
+	cv := reflect.ValueOf(&config).Elem()
+	opts := options{strict: true}
+	errs := joinError{}
+	// Maps keys to the field that uses them.
+	keys := map[string]string{}
+	// hasRest is true once a field is tagged `env:",rest"`.
+	hasRest := false
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		envKey, tagOpts := parseTag(field.Tag.Get("env"))
+		if !field.IsExported() {
+			continue
+		}
+		if isRestField(envKey, tagOpts) {
+			if err := checkRestField(field, hasRest); err != nil {
+				errs.Add(err)
+				continue
+			}
+			hasRest = true
+			continue
+		}
+		if envKey == "" {
+			continue
+		}
+		fieldErr := FieldError{Field: field.Name, Path: field.Name, Key: envKey}
+		if err := checkTag(field, tagOpts); err != nil {
+			fieldErr.Err, fieldErr.Cause = ErrInvalidTag, err
+			errs.Add(&fieldErr)
+			continue
+		}
+		// RefreshTTL fails on the same ttl tags.
+		if _, _, err := fieldTTL(field); err != nil {
+			fieldErr.Err, fieldErr.Cause = ErrInvalidTag, err
+			errs.Add(&fieldErr)
+			continue
+		}
+		if other, ok := keys[envKey]; ok {
+			fieldErr.Err, fieldErr.Cause = ErrInvalidTag, fmt.Errorf("key is also used by %v", other)
+			errs.Add(&fieldErr)
+			continue
+		}
+		keys[envKey] = field.Name
+		if field.Type == featureFlagsType {
+			continue
+		}
+		// Decoding a default tells us both that the type is supported and
+		// that the default is valid. Fields without a default get an empty
+		// value, which is only used to see if the type is supported.
+		fieldVal := cv.Field(i)
+		if lazy, ok := fieldVal.Addr().Interface().(lazyField); ok {
+			fieldVal = reflect.New(lazy.valueType()).Elem()
+		}
+		defaultValue, hasDefault := opts.fieldDefault(field)
+		if err := opts.decodeValue(fieldVal, defaultValue, tagOpts, opts.separator(field)); err != nil {
+			if hasDefault || err.Err == ErrUnsupportedFieldType {
+				fieldErr.Err, fieldErr.Cause = err.Err, err.Cause
+				errs.Add(&fieldErr)
+			}
+		}
+	}
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// checkTag looks for problems with a field's tags, such as unknown
+// options (often a typo) or options that contradict each other. We'd
+// rather fail loudly than silently ignore a misspelled option.
+func checkTag(field reflect.StructField, tagOpts tagOptions) error {
+	for _, opt := range tagOpts.names() {
+		name, value, hasValue := strings.Cut(opt, "=")
+		name = strings.TrimSpace(name)
+		switch {
+		case !knownTagOptions[name] && !tagValueOptions[name]:
+			return fmt.Errorf("unknown option %q", opt)
+		case tagValueOptions[name] && strings.TrimSpace(value) == "":
+			return fmt.Errorf("%v needs a value, like %v=KEY", name, name)
+		case knownTagOptions[name] && hasValue:
+			return fmt.Errorf("%v doesn't take a value", name)
+		}
+	}
+	_, hasDefault := field.Tag.Lookup("default")
+	// required and present mean the same thing: the key must be set.
+	for _, name := range []string{"required", "present"} {
+		switch {
+		case tagOpts.Contains(name) && tagOpts.Contains("optional"):
+			return fmt.Errorf("can't be both %v and optional", name)
+		case tagOpts.Contains(name) && hasDefault:
+			return fmt.Errorf("%v fields can't have a default", name)
+		}
+	}
+	if err := checkRequiredIf(field, tagOpts); err != nil {
+		return err
+	}
+	if err := checkGroup(field, tagOpts); err != nil {
+		return err
+	}
+	switch {
+	case tagOpts.Contains("trim") && tagOpts.Contains("notrim"):
+		return fmt.Errorf("can't be both trim and notrim")
+	case tagOpts.Contains("lower") && tagOpts.Contains("upper"):
+		return fmt.Errorf("can't be both lower and upper")
+	case tagOpts.Contains("expand") && tagOpts.Contains("noexpand"):
+		return fmt.Errorf("can't be both expand and noexpand")
+	case tagOpts.Contains("mustexist") && !tagOpts.Contains("path"):
+		return fmt.Errorf("mustexist needs path")
+	}
+	return nil
+}
+
+// names returns each option in o.
+func (o tagOptions) names() []string {
+	if o == "" {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(string(o), ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
@@ -0,0 +1,59 @@
+package dotconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// Template renders env files as [text/template] templates before parsing
+// them, so values can be computed at startup instead of in a separate
+// templating step. Fields of data are available as usual and the env
+// function reads from the current environment:
+//
+//	# .env.tmpl
+//	ADDR={{ .PodIP }}:8080
+//	CACHE_DIR={{ env "HOME" }}/.cache/myapp
+//
+//	conf, err := dotconfig.FromFileName[AppConfig](".env.tmpl",
+//		dotconfig.Template(map[string]any{"PodIP": podIP}))
+//
+// Referencing a key that isn't in data is an error rather than silently
+// rendering "<no value>". Data can be empty if you only need env.
+func Template(data map[string]any) DecodeOption {
+	return funcOption(func(o *options) {
+		if data == nil {
+			data = map[string]any{}
+		}
+		o.TemplateData = data
+	})
+}
+
+// templateFuncs returns the functions available in templates rendered
+// by [Template].
+func (o options) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"env": func(key string) string {
+			value, _ := o.getenv(key)
+			return value
+		},
+	}
+}
+
+// renderTemplate executes the template read from r against data.
+func (o options) renderTemplate(r io.Reader, data map[string]any) (io.Reader, error) {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("env").Funcs(o.templateFuncs()).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parsing env template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rendering env template: %w", err)
+	}
+	return &buf, nil
+}
@@ -0,0 +1,67 @@
+package dotconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutSource gives each fetch from src at most d to finish, so a hung
+// metadata endpoint fails startup quickly with an error like "source
+// vault timed out after 5s" instead of hanging it:
+//
+//	vault := dotconfig.TimeoutSource(dotconfig.NamedSource("vault", vaultSource), 5*time.Second)
+//
+// Use the [LoadTimeout] option for a deadline on the whole load.
+func TimeoutSource(src Source, d time.Duration) Source {
+	return timeoutSource{Source: src, timeout: d}
+}
+
+type timeoutSource struct {
+	Source
+	timeout time.Duration
+}
+
+func (s timeoutSource) Fetch(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	values, err := fetchWithContext(ctx, s.Source)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("source %v timed out after %v: %w", sourceName(s.Source), s.timeout, context.DeadlineExceeded)
+	}
+	return values, err
+}
+
+// LoadTimeout sets a deadline for fetching from every [Source] in
+// [FromSource] and [FromSources], on top of any deadline ctx already
+// has. Use [TimeoutSource] to limit sources individually.
+func LoadTimeout(d time.Duration) DecodeOption {
+	return funcOption(func(o *options) {
+		o.LoadTimeout = d
+	})
+}
+
+// fetchWithContext fetches from src but returns as soon as ctx is done,
+// even if src ignores ctx. The fetch is left to finish in the
+// background in that case.
+func fetchWithContext(ctx context.Context, src Source) (map[string]string, error) {
+	type result struct {
+		values map[string]string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		values, err := src.Fetch(ctx)
+		done <- result{values, err}
+	}()
+	select {
+	case r := <-done:
+		return r.values, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("source %v timed out: %w", sourceName(src), ctx.Err())
+		}
+		return nil, ctx.Err()
+	}
+}
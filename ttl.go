@@ -0,0 +1,131 @@
+package dotconfig
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// RefreshTTL re-fetches fields that have a ttl tag from src each time
+// their TTL expires and swaps the new values into store with
+// [Store.Update]. The rest of the config stays as it is. This suits
+// short-lived credentials, like database passwords issued by Vault:
+//
+//	type AppConfig struct {
+//		Region     string `env:"REGION"`
+//		DBPassword string `env:"DB_PASSWORD,secret" ttl:"15m"`
+//	}
+//
+//	err := dotconfig.RefreshTTL(ctx, store, vault)
+//
+// Fields with the same TTL are refreshed together. If a fetch fails, the
+// store keeps its current values and the error goes to [Store.OnError].
+// RefreshTTL returns an [ErrInvalidTag] error if a ttl tag isn't a
+// positive duration, and otherwise returns immediately and stops
+// refreshing when ctx is done.
+func RefreshTTL[T any](ctx context.Context, store *Store[T], src Source, opts ...DecodeOption) error {
+	ops := optsFromVariadic(opts)
+	groups, err := ttlFields(reflect.TypeFor[T](), ops)
+	if err != nil {
+		return err
+	}
+	// Each group copies the current config, sets its fields and stores
+	// the copy while holding mu, so it can't store a copy from before
+	// another group's refresh.
+	var mu sync.Mutex
+	for ttl, fields := range groups {
+		go func() {
+			ticker := time.NewTicker(ttl)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					fresh, err := refreshFields[T](ctx, src, fields, ops)
+					mu.Lock()
+					current := store.Get()
+					if err == nil {
+						cv := reflect.ValueOf(&current).Elem()
+						for j, i := range fields {
+							cv.Field(i).Set(fresh.Field(j))
+						}
+					}
+					store.Update(current, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// ttlFields groups the indexes of the fields in ct that have a ttl tag by
+// their TTL.
+func ttlFields(ct reflect.Type, ops options) (map[time.Duration][]int, error) {
+	if ct.Kind() != reflect.Struct {
+		return nil, ErrConfigMustBeStruct
+	}
+	groups := map[time.Duration][]int{}
+	for i := 0; i < ct.NumField(); i++ {
+		field := ct.Field(i)
+		ttl, ok, err := fieldTTL(field)
+		if !ok || !field.IsExported() {
+			continue
+		}
+		if err != nil {
+			envKey, _ := ops.fieldTag(field)
+			return nil, &FieldError{Field: field.Name, Path: field.Name, Key: envKey, Err: ErrInvalidTag, Cause: err}
+		}
+		groups[ttl] = append(groups[ttl], i)
+	}
+	return groups, nil
+}
+
+// fieldTTL returns the duration from field's ttl tag, if it has one,
+// or an error if it isn't a positive duration.
+func fieldTTL(field reflect.StructField) (time.Duration, bool, error) {
+	tag, ok := field.Tag.Lookup("ttl")
+	if !ok {
+		return 0, false, nil
+	}
+	ttl, err := time.ParseDuration(tag)
+	if err == nil && ttl <= 0 {
+		err = fmt.Errorf("ttl must be positive")
+	}
+	return ttl, true, err
+}
+
+// refreshFields fetches src, sets the keys for the fields of T at the
+// given indexes and decodes just those fields. It returns a struct with
+// a field for each index, in the same order.
+func refreshFields[T any](ctx context.Context, src Source, fields []int, ops options) (reflect.Value, error) {
+	values, err := ops.fetch(ctx, src)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	// Groups refresh at the same time, so each refresh sets its values in
+	// its own copy of the environment.
+	ops.Environ, ops.private = maps.Clone(ops.Environ), maps.Clone(ops.private)
+	ct := reflect.TypeFor[T]()
+	res := ops.newResult()
+	group := make([]reflect.StructField, len(fields))
+	for j, i := range fields {
+		field := ct.Field(i)
+		envKey, _ := ops.fieldTag(field)
+		if value, ok := values[envKey]; ok {
+			res.setenv(envKey, value, sourceName(src), 0)
+		}
+		group[j] = reflect.StructField{Name: field.Name, Type: field.Type, Tag: field.Tag}
+	}
+	// Other fields might not be in the environment at all, like with
+	// WithEnviron, so only the group's fields are decoded.
+	fresh := reflect.New(reflect.StructOf(group)).Elem()
+	if err := decodeEnv(fresh, ops, &res); err != nil {
+		return reflect.Value{}, err
+	}
+	return fresh, nil
+}
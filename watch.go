@@ -0,0 +1,74 @@
+package dotconfig
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long we wait after a file event before
+// reloading. Editors often write a file in several steps, and we
+// only want to decode once they're done.
+const watchDebounce = 50 * time.Millisecond
+
+// Watch calls [FromFileName] whenever the file called filename changes
+// and delivers the result to onChange. This lets long-running services
+// pick up tweaks to non-secret settings without a restart:
+//
+//	err := dotconfig.Watch(ctx, ".env", func(conf AppConfig, err error) {
+//		if err != nil {
+//			log.Printf("reloading config: %v", err)
+//			return
+//		}
+//		// Use new config
+//	})
+//
+// Watch returns after the watcher is set up and stops watching when ctx
+// is done. onChange is called from a single goroutine so calls never
+// overlap. Note that keys removed from the file are not removed from
+// the environment, so they keep their previous value.
+func Watch[T any](ctx context.Context, filename string, onChange func(T, error), opts ...DecodeOption) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watch the directory instead of the file. Many editors (and
+	// Kubernetes when updating a mounted ConfigMap) replace the file
+	// instead of writing to it, which would end a watch on the file.
+	if err := watcher.Add(filepath.Dir(filename)); err != nil {
+		watcher.Close()
+		return err
+	}
+	go func() {
+		defer watcher.Close()
+		name := filepath.Clean(filename)
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != name || event.Op == fsnotify.Chmod {
+					continue
+				}
+				timer.Reset(watchDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				var config T
+				onChange(config, err)
+			case <-timer.C:
+				onChange(FromFileName[T](filename, opts...))
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,48 @@
+package dotconfig_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DeanPDX/dotconfig"
+)
+
+func TestWatch(t *testing.T) {
+	type watchConfig struct {
+		Greeting string `env:"WATCH_GREETING"`
+	}
+	name := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(name, []byte("WATCH_GREETING=hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan watchConfig, 1)
+	err := dotconfig.Watch(ctx, name, func(config watchConfig, err error) {
+		if err != nil {
+			t.Errorf("Didn't expect error. Got %v.", err)
+			return
+		}
+		select {
+		case changes <- config:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error. Got %v.", err)
+	}
+	if err := os.WriteFile(name, []byte("WATCH_GREETING=goodbye"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case config := <-changes:
+		if config.Greeting != "goodbye" {
+			t.Errorf("Expected reloaded greeting. Got %q.", config.Greeting)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for change.")
+	}
+}